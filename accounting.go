@@ -0,0 +1,185 @@
+// Copyright 2019 Miles Barr <milesbarr2@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marshaler
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ParseAccounting parses an amount written in accounting notation, such as
+// "($1,234.00)", "-$5.10", "€12", "12.50 USD" or "1,000.00 CR", and returns
+// its value together with the currency symbol or ISO 4217 code it contained,
+// if any. Enclosing parentheses, a leading or trailing minus sign and a CR or
+// DR marker make the amount negative as described by NegativeDebits. The
+// number itself is parsed according to the rest of nf. Since amounts are
+// usually written with digit grouping, the grouping character and decimal
+// separator are detected as by LocaleAuto if nf.Locale is not set.
+func (nf NumberFormat) ParseAccounting(s string, bitSize int) (float64, string, error) {
+	n, currency, ok := nf.stripAccounting(strings.TrimSpace(s))
+	if !ok {
+		return 0, "", numError("ParseFloat", s, strconv.ErrSyntax)
+	}
+	if nf.Locale == (Locale{}) {
+		nf.Locale = LocaleAuto
+	}
+	f, err := nf.parseFloat(n, bitSize)
+	if err != nil {
+		return 0, "", err
+	}
+	return f, currency, nil
+}
+
+// stripAccounting removes currency symbols, currency codes and sign markers
+// from s and returns the remaining number, prefixed with '-' if it is
+// negative, and the currency that was removed.
+func (nf NumberFormat) stripAccounting(s string) (string, string, bool) {
+	var currency string
+	negatives := 0
+	for {
+		before := s
+		switch {
+		case len(s) > 1 && s[0] == '(' && s[len(s)-1] == ')':
+			s = s[1 : len(s)-1]
+			negatives++
+		case strings.HasPrefix(s, "-") || strings.HasPrefix(s, "−"):
+			s = s[len(leadingRune(s)):]
+			negatives++
+		case strings.HasSuffix(s, "-") || strings.HasSuffix(s, "−"):
+			s = s[:len(s)-len(trailingRune(s))]
+			negatives++
+		case strings.HasPrefix(s, "+"):
+			s = s[1:]
+		case hasMarker(s, "CR"):
+			s = s[:len(s)-2]
+			if !nf.NegativeDebits {
+				negatives++
+			}
+		case hasMarker(s, "DR"):
+			s = s[:len(s)-2]
+			if nf.NegativeDebits {
+				negatives++
+			}
+		default:
+			c, rest := cutCurrency(s)
+			if c == "" {
+				break
+			}
+			if currency != "" && currency != c {
+				return "", "", false
+			}
+			currency, s = c, rest
+		}
+		s = strings.TrimSpace(s)
+		if s == before {
+			break
+		}
+	}
+	if s == "" || negatives > 1 {
+		return "", "", false
+	}
+	if negatives == 1 {
+		s = "-" + s
+	}
+	return s, currency, true
+}
+
+// hasMarker reports whether s ends with the two-letter marker m, in any case,
+// preceded by a digit, a space or a currency symbol.
+func hasMarker(s, m string) bool {
+	if len(s) < 3 || !strings.EqualFold(s[len(s)-2:], m) {
+		return false
+	}
+	r := trailingRune(s[:len(s)-2])
+	return r != "" && (isDigit(r) || isSpace(r) || isCurrencySymbol(r))
+}
+
+// cutCurrency removes a currency symbol, such as "$", "US$" or "€", or an ISO
+// 4217 code, such as "USD", from the start or end of s. It returns the
+// currency and the rest of s, or an empty currency if there is none.
+func cutCurrency(s string) (string, string) {
+	// Leading symbol, optionally preceded by a short country prefix.
+	i := 0
+	for i < len(s) && i < 3 && s[i] >= 'A' && s[i] <= 'Z' {
+		i++
+	}
+	if r := leadingRune(s[i:]); isCurrencySymbol(r) {
+		j := i + len(r)
+		return s[:j], s[j:]
+	}
+	// Trailing symbol.
+	if r := trailingRune(s); isCurrencySymbol(r) {
+		return r, s[:len(s)-len(r)]
+	}
+	// Leading or trailing ISO 4217 code. Something must remain so that
+	// strings like "INF" are left for the number parser.
+	if len(s) > 3 && isCurrencyCode(s[:3]) && !isLetter(s[3]) {
+		return s[:3], s[3:]
+	}
+	if len(s) > 3 && isCurrencyCode(s[len(s)-3:]) && !isLetter(s[len(s)-4]) {
+		return s[len(s)-3:], s[:len(s)-3]
+	}
+	return "", s
+}
+
+// isCurrencyCode reports whether s looks like an ISO 4217 currency code.
+func isCurrencyCode(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 'A' || s[i] > 'Z' {
+			return false
+		}
+	}
+	return len(s) == 3
+}
+
+// isCurrencySymbol reports whether the single rune string r is a currency
+// symbol.
+func isCurrencySymbol(r string) bool {
+	rs := []rune(r)
+	return len(rs) == 1 && unicode.Is(unicode.Sc, rs[0])
+}
+
+func isDigit(r string) bool {
+	return len(r) == 1 && r[0] >= '0' && r[0] <= '9'
+}
+
+func isSpace(r string) bool {
+	rs := []rune(r)
+	return len(rs) == 1 && unicode.IsSpace(rs[0])
+}
+
+func isLetter(b byte) bool {
+	return b >= 'A' && b <= 'Z' || b >= 'a' && b <= 'z'
+}
+
+// leadingRune returns the first rune of s as a string.
+func leadingRune(s string) string {
+	for i := range s {
+		if i > 0 {
+			return s[:i]
+		}
+	}
+	return s
+}
+
+// trailingRune returns the last rune of s as a string.
+func trailingRune(s string) string {
+	i := strings.LastIndexFunc(s, func(rune) bool { return true })
+	if i < 0 {
+		return ""
+	}
+	return s[i:]
+}
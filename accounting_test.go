@@ -0,0 +1,89 @@
+// Copyright 2019 Miles Barr <milesbarr2@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marshaler_test
+
+import (
+	"testing"
+
+	"github.com/jadefox10200/marshaler"
+)
+
+func TestAmountSet(t *testing.T) {
+	tests := []struct {
+		in       string
+		value    float64
+		currency string
+	}{
+		{"($1,234.00)", -1234, "$"},
+		{"$1,234,567.89", 1234567.89, "$"},
+		{"-$5.10", -5.1, "$"},
+		{"€12", 12, "€"},
+		{"1.234,50 €", 1234.5, "€"},
+		{"1,000.00 CR", -1000, ""},
+		{"1,000.00 DR", 1000, ""},
+		{"1000.00 cr", -1000, ""},
+		{"USD 1,234.50", 1234.5, "USD"},
+		{"1,234.50 USD", 1234.5, "USD"},
+		{"(1,234.50 USD)", -1234.5, "USD"},
+		{"EUR (1.234,50)", -1234.5, "EUR"},
+		{"12.50", 12.5, ""},
+	}
+	for _, tt := range tests {
+		var a marshaler.Amount
+		if err := a.Set(tt.in); err != nil {
+			t.Errorf("Set(%q): %v", tt.in, err)
+			continue
+		}
+		if a.Value != tt.value || a.Currency != tt.currency {
+			t.Errorf("Set(%q) = %v %q, want %v %q", tt.in, a.Value, a.Currency, tt.value, tt.currency)
+		}
+		var b marshaler.Amount
+		if err := b.Set(a.String()); err != nil || b != a {
+			t.Errorf("Set(%q) does not round-trip: %v, %v", a.String(), b, err)
+		}
+	}
+	for _, in := range []string{"$", "CR", "($1,234.00", "--5", "$5 €", "1,2,3"} {
+		var a marshaler.Amount
+		if err := a.Set(in); err == nil {
+			t.Errorf("Set(%q) = %v, want error", in, a)
+		}
+	}
+}
+
+func TestNumberFormatParseAccounting(t *testing.T) {
+	tests := []struct {
+		nf   marshaler.NumberFormat
+		in   string
+		want float64
+	}{
+		{marshaler.NumberFormat{Accounting: true}, "($1,234.00)", -1234},
+		{marshaler.NumberFormat{Accounting: true}, "1,000.00 CR", -1000},
+		{marshaler.NumberFormat{Accounting: true, NegativeDebits: true}, "1,000.00 DR", -1000},
+		{marshaler.NumberFormat{Accounting: true, NegativeDebits: true}, "1,000.00 CR", 1000},
+		{marshaler.NumberFormat{Accounting: true, Locale: marshaler.LocaleEuropean}, "(1.234,00 EUR)", -1234},
+		{marshaler.NumberFormat{Accounting: true, Locale: marshaler.LocaleEnglish}, "GBP 1,234", 1234},
+	}
+	for _, tt := range tests {
+		got, err := tt.nf.ParseFloat(tt.in, 64)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseFloat(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+	// An explicit locale is respected.
+	nf := marshaler.NumberFormat{Accounting: true, Locale: marshaler.LocaleC}
+	if f, err := nf.ParseFloat("($1,234.00)", 64); err == nil {
+		t.Errorf("ParseFloat with LocaleC = %v, want error", f)
+	}
+}
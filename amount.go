@@ -0,0 +1,78 @@
+// Copyright 2019 Miles Barr <milesbarr2@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marshaler
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// An Amount is an amount of money that can be unmarshaled from a string in
+// accounting notation, such as "($1,234.00)" or "12.50 USD". The currency
+// symbol or code found in the string, if any, is kept in Currency.
+type Amount struct {
+	Value    float64
+	Currency string
+}
+
+// String implements the flag.Value interface.
+func (a Amount) String() string {
+	s := strconv.FormatFloat(a.Value, 'f', -1, 64)
+	if a.Currency != "" {
+		s += " " + a.Currency
+	}
+	return s
+}
+
+// Set implements the flag.Value interface.
+func (a *Amount) Set(s string) error {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	f, currency, err := DefaultNumberFormat.ParseAccounting(s, 64)
+	if err != nil {
+		return fmt.Errorf("marshaler.Amount.Set: cannot parse \"%s\"", s)
+	}
+	*a = Amount{Value: f, Currency: currency}
+	return nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (a Amount) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (a *Amount) UnmarshalText(text []byte) error {
+	return a.Set(string(text))
+}
+
+// MarshalJSON implements the json.Marshaler interface. The amount is written
+// as a JSON string.
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.String())
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (a *Amount) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		b = []byte(s)
+	}
+	return a.UnmarshalText(b)
+}
@@ -0,0 +1,183 @@
+// Copyright 2019 Miles Barr <milesbarr2@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marshaler
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The default window of plausible dates used to infer the unit of a UNIX
+// timestamp.
+var (
+	defaultEpochMin = time.Date(1971, 1, 1, 0, 0, 0, 0, time.UTC)
+	defaultEpochMax = time.Date(2200, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// epochUnits are the powers of ten that convert seconds, milliseconds,
+// microseconds and nanoseconds to nanoseconds, in the order they are tried.
+var epochUnits = [...]int{9, 6, 3, 0}
+
+// EpochWindow returns the window of plausible dates p uses to infer the unit
+// of a UNIX timestamp.
+func (p *TimeParser) EpochWindow() (min, max time.Time) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.epochMin.IsZero() && p.epochMax.IsZero() {
+		return defaultEpochMin, defaultEpochMax
+	}
+	return p.epochMin, p.epochMax
+}
+
+// SetEpochWindow sets the window of plausible dates p uses to infer the unit
+// of a UNIX timestamp. A timestamp is read in the first of seconds,
+// milliseconds, microseconds and nanoseconds that yields a time in
+// [min, max). The default window, from 1971 until 2200, is narrow enough for
+// every timestamp to have at most one plausible unit.
+func (p *TimeParser) SetEpochWindow(min, max time.Time) {
+	p.mu.Lock()
+	p.epochMin, p.epochMax = min, max
+	p.mu.Unlock()
+}
+
+// SetEpochNumbers sets whether p parses a FlexibleTime written as a number,
+// which is then read as a UNIX timestamp in the unit inferred from its
+// magnitude. Numbers are only tried after the layouts of p.
+func (p *TimeParser) SetEpochNumbers(on bool) {
+	p.mu.Lock()
+	p.epoch = on
+	p.mu.Unlock()
+}
+
+// epochNumbers reports whether p parses a FlexibleTime written as a number.
+func (p *TimeParser) epochNumbers() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.epoch
+}
+
+// ParseEpoch parses s, a decimal number that may have a fractional part or
+// an exponent, as a UNIX timestamp whose unit is inferred from its magnitude
+// using the window of p.
+func (p *TimeParser) ParseEpoch(s string) (time.Time, error) {
+	min, max := p.EpochWindow()
+	s = strings.TrimSpace(s)
+	for _, unit := range epochUnits {
+		t, err := parseEpoch(s, unit)
+		if err != nil {
+			if unwrapNumError(err) == strconv.ErrSyntax {
+				return time.Time{}, err
+			}
+			continue
+		}
+		if !t.Before(min) && t.Before(max) {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("timestamp %s is outside the window from %s to %s", s, min.Format(time.RFC3339), max.Format(time.RFC3339))
+}
+
+// parseEpoch parses s as a UNIX timestamp in units of 10^(exp-9) seconds.
+// Fractions of a nanosecond are rounded half away from zero.
+func parseEpoch(s string, exp int) (time.Time, error) {
+	neg, digits, point, ok := splitDecimal(s)
+	if !ok {
+		return time.Time{}, numError("ParseEpoch", s, strconv.ErrSyntax)
+	}
+	n := "." + digits + "e" + strconv.Itoa(point+exp)
+	if neg {
+		n = "-" + n
+	}
+	n, err := RoundHalfAway.roundInteger(n)
+	if err != nil {
+		return time.Time{}, numError("ParseEpoch", s, err)
+	}
+	ns, _ := new(big.Int).SetString(n, 10)
+	sec, nsec := ns.DivMod(ns, big.NewInt(1e9), new(big.Int))
+	if !sec.IsInt64() {
+		return time.Time{}, numError("ParseEpoch", s, strconv.ErrRange)
+	}
+	return time.Unix(sec.Int64(), nsec.Int64()), nil
+}
+
+// formatEpoch formats t as a UNIX timestamp in units of 10^(exp-9) seconds,
+// with a fractional part only if t is not a whole number of units.
+func formatEpoch(t time.Time, exp int) string {
+	d := NewDecimal(t.Unix(), 0).Add(NewDecimal(int64(t.Nanosecond()), 9))
+	s := d.Mul(NewDecimal(1, int32(exp-9))).String()
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	return s
+}
+
+// An AutoTimestamp is a time.Time that is unmarshaled from a UNIX timestamp
+// in seconds, milliseconds, microseconds or nanoseconds, with the unit
+// inferred from its magnitude using the window of DefaultTimeParser. It may
+// be written as a JSON number or string and may have a fractional part. It is
+// marshaled as a UNIX timestamp in seconds.
+type AutoTimestamp time.Time
+
+// String implements the flag.Value interface.
+func (at AutoTimestamp) String() string {
+	return time.Time(at).String()
+}
+
+// Set implements the flag.Value interface.
+func (at *AutoTimestamp) Set(s string) error {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	t, err := DefaultTimeParser.ParseEpoch(s)
+	if err != nil {
+		return fmt.Errorf("marshaler.AutoTimestamp.Set: cannot parse \"%s\"", s)
+	}
+	*at = AutoTimestamp(t)
+	return nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (at AutoTimestamp) MarshalText() ([]byte, error) {
+	return []byte(formatEpoch(time.Time(at), 9)), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (at *AutoTimestamp) UnmarshalText(text []byte) error {
+	return at.Set(string(text))
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (at AutoTimestamp) MarshalJSON() ([]byte, error) {
+	return at.MarshalText()
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (at *AutoTimestamp) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		b = []byte(s)
+	}
+	return at.UnmarshalText(b)
+}
+
+// Format wraps time.Time.Format.
+func (at AutoTimestamp) Format(layout string) string {
+	return time.Time(at).Format(layout)
+}
@@ -0,0 +1,452 @@
+// Copyright 2019 Miles Barr <milesbarr2@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marshaler
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A CronSchedule is a cron schedule. It can be unmarshaled from a standard
+// 5-field expression, such as "30 9 * * MON-FRI", a 6-field expression whose
+// first field is seconds, or a descriptor: @yearly, @annually, @monthly,
+// @weekly, @daily, @midnight, @hourly or "@every <duration>", where the
+// duration is parsed like a RobustDuration. Fields may use names for months
+// and days of the week, ranges, steps and lists, and 7 is also Sunday. As in
+// Vixie cron, a day matches if either the day of the month or the day of
+// the week matches when both are restricted.
+//
+// Also as in Vixie cron, a schedule with a fixed minute and hour, such as
+// "30 1 * * *", fires once at the first valid instant after a time skipped
+// when clocks move forward, and only once when clocks are set back and its
+// time repeats. Schedules with a wildcard minute or hour, such as
+// "*/15 * * * *", follow the clock through both transitions.
+//
+// Schedules are evaluated in the location of the time passed to Next or
+// Prev, unless the expression starts with "CRON_TZ=<zone>" or "TZ=<zone>",
+// as in "CRON_TZ=America/New_York 0 9 * * *".
+type CronSchedule struct {
+	expr string
+	loc  *time.Location
+
+	second, minute, hour, dom, month, dow uint64
+
+	// anyDay reports whether the day of the month or the day of the week
+	// is unrestricted, so that days must match both.
+	anyDay bool
+
+	// wildcard reports whether the minute or the hour is unrestricted, so
+	// that cs is not adjusted for daylight saving time transitions.
+	wildcard bool
+
+	// every is the interval of an @every schedule.
+	every time.Duration
+}
+
+// cronField describes a field of a cron expression.
+type cronField struct {
+	min, max int
+	names    []string
+}
+
+var (
+	cronSeconds = cronField{0, 59, nil}
+	cronMinutes = cronField{0, 59, nil}
+	cronHours   = cronField{0, 23, nil}
+	cronDays    = cronField{1, 31, nil}
+	cronMonths  = cronField{1, 12, []string{"", "JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}}
+	cronWeek    = cronField{0, 7, []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}}
+)
+
+// cronDescriptors are the expressions that descriptors stand for.
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 0 1 1 *",
+	"@annually": "0 0 0 1 1 *",
+	"@monthly":  "0 0 0 1 * *",
+	"@weekly":   "0 0 0 * * 0",
+	"@daily":    "0 0 0 * * *",
+	"@midnight": "0 0 0 * * *",
+	"@hourly":   "0 0 * * * *",
+}
+
+// errCron is reported for an invalid cron expression.
+var errCron = errors.New("invalid cron expression")
+
+// ParseCronSchedule parses s as a CronSchedule.
+func ParseCronSchedule(s string) (CronSchedule, error) {
+	cs, err := parseCronSchedule(s)
+	if err != nil {
+		return CronSchedule{}, fmt.Errorf("marshaler.ParseCronSchedule: cannot parse \"%s\": %v", s, err)
+	}
+	return cs, nil
+}
+
+func parseCronSchedule(s string) (CronSchedule, error) {
+	fields := strings.Fields(s)
+	var cs CronSchedule
+	if len(fields) > 0 && (strings.HasPrefix(fields[0], "CRON_TZ=") || strings.HasPrefix(fields[0], "TZ=")) {
+		name := fields[0][strings.IndexByte(fields[0], '=')+1:]
+		if cs.loc = loadLocation(name); cs.loc == nil {
+			return CronSchedule{}, fmt.Errorf("unknown time zone %s", name)
+		}
+		fields = fields[1:]
+	}
+	if len(fields) == 0 {
+		return CronSchedule{}, errCron
+	}
+	cs.expr = strings.Join(fields, " ")
+	if strings.HasPrefix(fields[0], "@") {
+		name := strings.ToLower(fields[0])
+		if name == "@every" {
+			d, err := DefaultDurationFormat.ParseDuration(strings.Join(fields[1:], " "))
+			if err != nil || d <= 0 {
+				return CronSchedule{}, errors.New("invalid @every duration")
+			}
+			cs.every = d
+			return cs, nil
+		}
+		expr, ok := cronDescriptors[name]
+		if !ok || len(fields) > 1 {
+			return CronSchedule{}, fmt.Errorf("unknown descriptor %s", fields[0])
+		}
+		fields = strings.Fields(expr)
+	}
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return CronSchedule{}, fmt.Errorf("%d fields", len(fields))
+	}
+	var err error
+	for i, p := range []*uint64{&cs.second, &cs.minute, &cs.hour, &cs.dom, &cs.month, &cs.dow} {
+		f := []cronField{cronSeconds, cronMinutes, cronHours, cronDays, cronMonths, cronWeek}[i]
+		if *p, err = f.parse(fields[i]); err != nil {
+			return CronSchedule{}, err
+		}
+	}
+	// Sunday may be written as 7.
+	if cs.dow&(1<<7) != 0 {
+		cs.dow = cs.dow&^(1<<7) | 1
+	}
+	cs.anyDay = isCronStar(fields[3]) || isCronStar(fields[5])
+	cs.wildcard = isCronStar(fields[1]) || isCronStar(fields[2])
+	return cs, nil
+}
+
+// isCronStar reports whether a field is unrestricted.
+func isCronStar(field string) bool {
+	return strings.HasPrefix(field, "*") || strings.HasPrefix(field, "?")
+}
+
+// parse parses a field, which is a comma-separated list of values, ranges
+// and steps, as a bit set.
+func (f cronField) parse(field string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, step := part, 1
+		if i := strings.IndexByte(part, '/'); i >= 0 {
+			var err error
+			rng = part[:i]
+			if step, err = strconv.Atoi(part[i+1:]); err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid step in %s", part)
+			}
+		}
+		lo, hi := f.min, f.max
+		switch {
+		case rng == "*" || rng == "?":
+			if f.max == 7 {
+				hi = 6
+			}
+		case strings.Contains(rng, "-"):
+			i := strings.IndexByte(rng, '-')
+			var err1, err2 error
+			lo, err1 = f.value(rng[:i])
+			hi, err2 = f.value(rng[i+1:])
+			if err1 != nil || err2 != nil || lo > hi {
+				return 0, fmt.Errorf("invalid range %s", part)
+			}
+		default:
+			var err error
+			if lo, err = f.value(rng); err != nil {
+				return 0, err
+			}
+			if step == 1 {
+				hi = lo
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// value parses a number or name in the field.
+func (f cronField) value(s string) (int, error) {
+	for i, name := range f.names {
+		if name != "" && strings.EqualFold(s, name) {
+			return i, nil
+		}
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("invalid value %s", s)
+	}
+	return v, nil
+}
+
+// hasBit reports whether bit v of bits is set.
+func hasBit(bits uint64, v int) bool {
+	return bits&(1<<uint(v)) != 0
+}
+
+// dayMatches reports whether the day of t matches cs.
+func (cs CronSchedule) dayMatches(t time.Time) bool {
+	dom, dow := hasBit(cs.dom, t.Day()), hasBit(cs.dow, int(t.Weekday()))
+	if cs.anyDay {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// cronYears is the number of years Next and Prev search for a matching time.
+const cronYears = 30
+
+// cronAfter returns next, the midnight that starts a later day or month. If
+// that midnight falls in a daylight saving time gap and time.Date moved it
+// back to t or earlier, it is moved forward an hour at a time until it is
+// after t.
+func cronAfter(t, next time.Time) time.Time {
+	for !next.After(t) {
+		next = next.Add(time.Hour)
+	}
+	return next
+}
+
+// cronMaxShift is the largest amount by which clocks are set back.
+const cronMaxShift = 3 * time.Hour
+
+// cronShift returns the instant, to the second, at which the UTC offset
+// changed between a and b, and the amount by which clocks moved forward,
+// which is negative if they were set back. The amount is zero if the offsets
+// of a and b are equal.
+func cronShift(a, b time.Time) (time.Time, time.Duration) {
+	_, oa := a.Zone()
+	_, ob := b.Zone()
+	if oa == ob {
+		return b, 0
+	}
+	for b.Sub(a) > time.Second {
+		mid := a.Add(b.Sub(a) / 2).Truncate(time.Second)
+		if _, o := mid.Zone(); o == oa {
+			a = mid
+		} else {
+			b = mid
+		}
+	}
+	return b, time.Duration(ob-oa) * time.Second
+}
+
+// cronRepeat reports whether the wall clock time of t occurred before,
+// earlier the same day, because clocks were set back, and returns the
+// interval [start, end) of such repeated times.
+func cronRepeat(t time.Time) (start, end time.Time, ok bool) {
+	_, off := t.Zone()
+	_, prev := t.Add(-cronMaxShift).Zone()
+	d := time.Duration(prev-off) * time.Second
+	if d <= 0 {
+		return time.Time{}, time.Time{}, false
+	}
+	e := t.Add(-d)
+	if _, o := e.Zone(); o != prev {
+		return time.Time{}, time.Time{}, false
+	}
+	start, _ = cronShift(e, t)
+	return start, start.Add(d), true
+}
+
+// gap reports whether clocks moved forward between a and b, skipping a
+// wall clock time at which cs would have fired, and if so returns the
+// instant at which they moved.
+func (cs CronSchedule) gap(a, b time.Time) (time.Time, bool) {
+	if cs.wildcard {
+		return time.Time{}, false
+	}
+	if b.Before(a) {
+		a, b = b, a
+	}
+	at, d := cronShift(a, b)
+	if d <= 0 {
+		return time.Time{}, false
+	}
+	// Check the skipped wall clock times, written as UTC times.
+	y, m, day := at.Date()
+	h, min, sec := at.Clock()
+	end := time.Date(y, m, day, h, min, sec, 0, time.UTC)
+	for w := end.Add(-d); w.Before(end); w = w.Add(time.Minute) {
+		if hasBit(cs.month, int(w.Month())) && cs.dayMatches(w) && hasBit(cs.hour, w.Hour()) && hasBit(cs.minute, w.Minute()) {
+			return at, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// Location returns the time zone of cs, or nil if cs is evaluated in the
+// location of the times passed to Next and Prev.
+func (cs CronSchedule) Location() *time.Location {
+	return cs.loc
+}
+
+// In returns cs evaluated in loc.
+func (cs CronSchedule) In(loc *time.Location) CronSchedule {
+	cs.loc = loc
+	return cs
+}
+
+// Next returns the first time after t at which cs fires, in the location of
+// t, or the zero time if there is none. An @every schedule fires at t plus
+// its interval, rounded down to the second.
+func (cs CronSchedule) Next(t time.Time) time.Time {
+	if cs.every > 0 {
+		return t.Add(cs.every - time.Duration(t.Nanosecond()))
+	}
+	if cs.second == 0 {
+		return time.Time{}
+	}
+	orig := t.Location()
+	if cs.loc != nil {
+		t = t.In(cs.loc)
+	}
+	loc := t.Location()
+	next := t.Add(time.Second - time.Duration(t.Nanosecond()))
+	if at, ok := cs.gap(t, next); ok {
+		return at.In(orig)
+	}
+	t = next
+	for limit := t.Year() + cronYears; t.Year() <= limit; {
+		y, m, d := t.Date()
+		next = t
+		switch {
+		case !hasBit(cs.month, int(m)):
+			next = cronAfter(t, time.Date(y, m+1, 1, 0, 0, 0, 0, loc))
+		case !cs.dayMatches(t):
+			next = cronAfter(t, time.Date(y, m, d+1, 0, 0, 0, 0, loc))
+		case !hasBit(cs.hour, t.Hour()):
+			// Step in absolute time, since the next hour on the wall
+			// clock may not exist.
+			next = t.Truncate(time.Minute).Add(time.Duration(60-t.Minute()) * time.Minute)
+		case !hasBit(cs.minute, t.Minute()):
+			next = t.Truncate(time.Minute).Add(time.Minute)
+		case !hasBit(cs.second, t.Second()):
+			next = t.Add(time.Second)
+		default:
+			if _, end, ok := cronRepeat(t); ok && !cs.wildcard {
+				next = end
+				break
+			}
+			return t.In(orig)
+		}
+		if at, ok := cs.gap(t, next); ok {
+			return at.In(orig)
+		}
+		t = next
+	}
+	return time.Time{}
+}
+
+// Prev returns the last time before t at which cs fired, in the location of
+// t, or the zero time if there is none. An @every schedule fired at t minus
+// its interval, rounded down to the second.
+func (cs CronSchedule) Prev(t time.Time) time.Time {
+	if cs.every > 0 {
+		return t.Add(-cs.every - time.Duration(t.Nanosecond()))
+	}
+	if cs.second == 0 {
+		return time.Time{}
+	}
+	orig := t.Location()
+	if cs.loc != nil {
+		t = t.In(cs.loc)
+	}
+	loc := t.Location()
+	if t.Nanosecond() > 0 {
+		t = t.Add(-time.Duration(t.Nanosecond()))
+	} else {
+		t = t.Add(-time.Second)
+	}
+	for limit := t.Year() - cronYears; t.Year() >= limit; {
+		y, m, d := t.Date()
+		prev := t
+		switch {
+		case !hasBit(cs.month, int(m)):
+			prev = time.Date(y, m, 1, 0, 0, 0, 0, loc).Add(-time.Second)
+		case !cs.dayMatches(t):
+			prev = time.Date(y, m, d, 0, 0, 0, 0, loc).Add(-time.Second)
+		case !hasBit(cs.hour, t.Hour()):
+			prev = t.Truncate(time.Minute).Add(-time.Duration(t.Minute())*time.Minute - time.Second)
+		case !hasBit(cs.minute, t.Minute()):
+			prev = t.Truncate(time.Minute).Add(-time.Second)
+		case !hasBit(cs.second, t.Second()):
+			prev = t.Add(-time.Second)
+		default:
+			if start, _, ok := cronRepeat(t); ok && !cs.wildcard {
+				prev = start.Add(-time.Second)
+				break
+			}
+			return t.In(orig)
+		}
+		if at, ok := cs.gap(prev, t); ok {
+			return at.In(orig)
+		}
+		t = prev
+	}
+	return time.Time{}
+}
+
+// String implements the flag.Value interface.
+func (cs CronSchedule) String() string {
+	if cs.loc != nil {
+		return "CRON_TZ=" + cs.loc.String() + " " + cs.expr
+	}
+	return cs.expr
+}
+
+// Set implements the flag.Value interface.
+func (cs *CronSchedule) Set(s string) error {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	v, err := parseCronSchedule(s)
+	if err != nil {
+		return fmt.Errorf("marshaler.CronSchedule.Set: cannot parse \"%s\": %v", s, err)
+	}
+	*cs = v
+	return nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (cs CronSchedule) MarshalText() ([]byte, error) {
+	return []byte(cs.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (cs *CronSchedule) UnmarshalText(text []byte) error {
+	return cs.Set(string(text))
+}
@@ -0,0 +1,240 @@
+// Copyright 2019 Miles Barr <milesbarr2@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marshaler_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jadefox10200/marshaler"
+)
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("time zone %s: %v", name, err)
+	}
+	return loc
+}
+
+func TestCronScheduleNextPrev(t *testing.T) {
+	// A Wednesday.
+	from := time.Date(2019, 7, 17, 13, 45, 30, 500, time.UTC)
+	tests := []struct {
+		expr, next, prev string
+	}{
+		// 5-field expressions.
+		{"* * * * *", "2019-07-17T13:46:00Z", "2019-07-17T13:45:00Z"},
+		{"30 9 * * MON-FRI", "2019-07-18T09:30:00Z", "2019-07-17T09:30:00Z"},
+		{"0 0 1 * *", "2019-08-01T00:00:00Z", "2019-07-01T00:00:00Z"},
+		{"0 9-17/4 * * *", "2019-07-17T17:00:00Z", "2019-07-17T13:00:00Z"},
+		{"0 0,12 * jan,Jul ?", "2019-07-18T00:00:00Z", "2019-07-17T12:00:00Z"},
+		{"0 0 29 2 *", "2020-02-29T00:00:00Z", "2016-02-29T00:00:00Z"},
+		// 6-field expressions start with seconds.
+		{"*/15 * * * * *", "2019-07-17T13:45:45Z", "2019-07-17T13:45:30Z"},
+		{"10 0 14 * * *", "2019-07-17T14:00:10Z", "2019-07-16T14:00:10Z"},
+		// Descriptors.
+		{"@hourly", "2019-07-17T14:00:00Z", "2019-07-17T13:00:00Z"},
+		{"@daily", "2019-07-18T00:00:00Z", "2019-07-17T00:00:00Z"},
+		{"@midnight", "2019-07-18T00:00:00Z", "2019-07-17T00:00:00Z"},
+		{"@weekly", "2019-07-21T00:00:00Z", "2019-07-14T00:00:00Z"},
+		{"@monthly", "2019-08-01T00:00:00Z", "2019-07-01T00:00:00Z"},
+		{"@yearly", "2020-01-01T00:00:00Z", "2019-01-01T00:00:00Z"},
+		{"@annually", "2020-01-01T00:00:00Z", "2019-01-01T00:00:00Z"},
+		// Sunday may be written as 0 or 7.
+		{"0 0 * * 0", "2019-07-21T00:00:00Z", "2019-07-14T00:00:00Z"},
+		{"0 0 * * 7", "2019-07-21T00:00:00Z", "2019-07-14T00:00:00Z"},
+		{"0 0 * * 5-7", "2019-07-19T00:00:00Z", "2019-07-14T00:00:00Z"},
+		// A day matches either a restricted day of the month or a
+		// restricted day of the week.
+		{"0 12 13 * 5", "2019-07-19T12:00:00Z", "2019-07-13T12:00:00Z"},
+		{"0 12 13 * *", "2019-08-13T12:00:00Z", "2019-07-13T12:00:00Z"},
+		{"0 12 * * 5", "2019-07-19T12:00:00Z", "2019-07-12T12:00:00Z"},
+		// Time zones.
+		{"CRON_TZ=America/New_York 0 9 * * *", "2019-07-18T13:00:00Z", "2019-07-17T13:00:00Z"},
+		{"TZ=Asia/Tokyo 0 9 * * *", "2019-07-18T00:00:00Z", "2019-07-17T00:00:00Z"},
+		{"CRON_TZ=Asia/Kolkata @daily", "2019-07-17T18:30:00Z", "2019-07-16T18:30:00Z"},
+		// @every schedules are relative to the given time.
+		{"@every 5m", "2019-07-17T13:50:30Z", "2019-07-17T13:40:30Z"},
+		{"@every 1h30m", "2019-07-17T15:15:30Z", "2019-07-17T12:15:30Z"},
+	}
+	for _, tt := range tests {
+		cs, err := marshaler.ParseCronSchedule(tt.expr)
+		if err != nil {
+			t.Errorf("ParseCronSchedule(%q): %v", tt.expr, err)
+			continue
+		}
+		next, prev := cs.Next(from), cs.Prev(from)
+		if got := next.Format(time.RFC3339); got != tt.next || next.Location() != time.UTC {
+			t.Errorf("%q.Next = %s, want %s", tt.expr, next, tt.next)
+		}
+		if got := prev.Format(time.RFC3339); got != tt.prev || prev.Location() != time.UTC {
+			t.Errorf("%q.Prev = %s, want %s", tt.expr, prev, tt.prev)
+		}
+	}
+}
+
+func TestCronScheduleNever(t *testing.T) {
+	cs, err := marshaler.ParseCronSchedule("0 0 30 2 *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := time.Date(2019, 7, 17, 0, 0, 0, 0, time.UTC)
+	if next := cs.Next(from); !next.IsZero() {
+		t.Errorf("Next = %s, want the zero time", next)
+	}
+	if prev := cs.Prev(from); !prev.IsZero() {
+		t.Errorf("Prev = %s, want the zero time", prev)
+	}
+}
+
+func TestCronScheduleDST(t *testing.T) {
+	ny := mustLoadLocation(t, "America/New_York")
+	tests := []struct {
+		name, expr string
+		from       time.Time
+		next       []string
+		prev       string
+	}{
+		{
+			name: "fixed time in repeated hour fires once",
+			expr: "CRON_TZ=America/New_York 30 1 * * *",
+			from: time.Date(2021, 11, 7, 0, 0, 0, 0, ny),
+			next: []string{"2021-11-07T01:30:00-04:00", "2021-11-08T01:30:00-05:00"},
+		},
+		{
+			name: "fixed time in repeated hour fired first occurrence",
+			expr: "30 1 * * *",
+			from: time.Date(2021, 11, 7, 3, 0, 0, 0, ny),
+			prev: "2021-11-07T01:30:00-04:00",
+		},
+		{
+			name: "wildcard hour follows the clock back",
+			expr: "30 * * * *",
+			from: time.Date(2021, 11, 7, 1, 0, 0, 0, ny),
+			next: []string{"2021-11-07T01:30:00-04:00", "2021-11-07T01:30:00-05:00", "2021-11-07T02:30:00-05:00"},
+			prev: "2021-11-07T00:30:00-04:00",
+		},
+		{
+			name: "skipped fixed time fires after the gap",
+			expr: "30 2 * * *",
+			from: time.Date(2021, 3, 14, 0, 0, 0, 0, ny),
+			next: []string{"2021-03-14T03:00:00-04:00", "2021-03-15T02:30:00-04:00"},
+		},
+		{
+			name: "skipped fixed time fired after the gap",
+			expr: "30 2 * * *",
+			from: time.Date(2021, 3, 14, 12, 0, 0, 0, ny),
+			prev: "2021-03-14T03:00:00-04:00",
+		},
+		{
+			name: "skipped time fires after the gap from just before it",
+			expr: "0 2 * * *",
+			from: time.Date(2021, 3, 14, 1, 59, 59, 0, ny),
+			next: []string{"2021-03-14T03:00:00-04:00"},
+		},
+		{
+			name: "wildcard hour follows the clock forward",
+			expr: "30 * * * *",
+			from: time.Date(2021, 3, 14, 1, 45, 0, 0, ny),
+			next: []string{"2021-03-14T03:30:00-04:00"},
+			prev: "2021-03-14T01:30:00-05:00",
+		},
+		{
+			name: "clocks set back east of UTC",
+			expr: "CRON_TZ=Europe/Berlin 30 2 * * *",
+			from: time.Date(2021, 10, 31, 0, 0, 0, 0, time.UTC),
+			next: []string{"2021-10-31T00:30:00Z", "2021-11-01T01:30:00Z"},
+		},
+		{
+			name: "clocks moved forward east of UTC",
+			expr: "CRON_TZ=Europe/Berlin 30 2 * * *",
+			from: time.Date(2021, 3, 28, 0, 0, 0, 0, time.UTC),
+			next: []string{"2021-03-28T01:00:00Z", "2021-03-29T00:30:00Z"},
+		},
+		{
+			name: "skipped time on another day does not fire",
+			expr: "30 2 * * MON",
+			from: time.Date(2021, 3, 13, 0, 0, 0, 0, ny),
+			next: []string{"2021-03-15T02:30:00-04:00"},
+			prev: "2021-03-08T02:30:00-05:00",
+		},
+	}
+	for _, tt := range tests {
+		cs, err := marshaler.ParseCronSchedule(tt.expr)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		next := tt.from
+		for _, want := range tt.next {
+			next = cs.Next(next)
+			if got := next.Format(time.RFC3339); got != want {
+				t.Errorf("%s: Next = %s, want %s", tt.name, got, want)
+				break
+			}
+		}
+		if tt.prev != "" {
+			if got := cs.Prev(tt.from).Format(time.RFC3339); got != tt.prev {
+				t.Errorf("%s: Prev = %s, want %s", tt.name, got, tt.prev)
+			}
+		}
+	}
+}
+
+func TestCronScheduleSet(t *testing.T) {
+	tests := []struct {
+		in, out string
+	}{
+		{"30 9 * * MON-FRI", "30 9 * * MON-FRI"},
+		{"  0  0 * * *  ", "0 0 * * *"},
+		{"TZ=UTC  @daily", "CRON_TZ=UTC @daily"},
+		{"CRON_TZ=Europe/London 0 0 12 * * *", "CRON_TZ=Europe/London 0 0 12 * * *"},
+		{"@every 90s", "@every 90s"},
+	}
+	for _, tt := range tests {
+		var cs marshaler.CronSchedule
+		if err := cs.Set(tt.in); err != nil {
+			t.Errorf("Set(%q): %v", tt.in, err)
+			continue
+		}
+		if got := cs.String(); got != tt.out {
+			t.Errorf("Set(%q).String() = %q, want %q", tt.in, got, tt.out)
+		}
+	}
+	for _, in := range []string{
+		"* * * *",
+		"* * * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"* * * FOO *",
+		"@reboot",
+		"@daily *",
+		"@every",
+		"@every -5m",
+		"TZ=Nowhere/City * * * * *",
+		"CRON_TZ=UTC",
+	} {
+		var cs marshaler.CronSchedule
+		if err := cs.Set(in); err == nil {
+			t.Errorf("Set(%q) = %q, want error", in, cs)
+		}
+	}
+}
@@ -24,18 +24,28 @@ import (
 // YYYY-MM-DD format.
 type Date time.Time
 
+// dateLayout is the layout of a Date.
+const dateLayout = "2006-01-02"
+
 // Strings implements the flag.Value interface.
 func (d Date) String() string {
-	return time.Time(d).Format("2006-01-02")
+	return time.Time(d).Format(dateLayout)
 }
 
-// Set implements the flag.Value interface.
+// Set implements the flag.Value interface. Strings without an explicit time
+// zone are interpreted in the location of DefaultTimeParser.
 func (d *Date) Set(s string) error {
+	return d.SetInLocation(s, DefaultTimeParser.Location())
+}
+
+// SetInLocation is like Set but interprets strings without an explicit time
+// zone in loc.
+func (d *Date) SetInLocation(s string, loc *time.Location) error {
 	s = strings.TrimSpace(s)
 	if s == "" {
 		return nil
 	}
-	t, err := time.Parse("2006-01-02", s)
+	t, err := parseTime(s, loc, dateLayout)
 	if err != nil {
 		return fmt.Errorf("marshaler.Date.Set: cannot parse \"%s\"", s)
 	}
@@ -57,3 +67,62 @@ func (d *Date) UnmarshalText(text []byte) error {
 func (d Date) Format(layout string) string {
 	return time.Time(d).Format(layout)
 }
+
+// newDate returns the Date of the given day in the location of
+// DefaultTimeParser. The month and day may be outside their usual ranges and
+// are normalized like time.Date.
+func newDate(year int, month time.Month, day int) Date {
+	return Date(time.Date(year, month, day, 0, 0, 0, 0, DefaultTimeParser.Location()))
+}
+
+// midnightUTC returns the UTC midnight of the calendar day of d.
+func (d Date) midnightUTC() time.Time {
+	y, m, day := time.Time(d).Date()
+	return time.Date(y, m, day, 0, 0, 0, 0, time.UTC)
+}
+
+// AddDays returns d plus n calendar days.
+func (d Date) AddDays(n int) Date {
+	return Date(time.Time(d).AddDate(0, 0, n))
+}
+
+// AddMonths returns d plus n months. If the day of d does not exist in the
+// resulting month, the last day of that month is used, so January 31 plus
+// one month is February 28 or 29.
+func (d Date) AddMonths(n int) Date {
+	t := time.Time(d)
+	y, m, day := t.Date()
+	if last := time.Date(y, m+time.Month(n)+1, 0, 0, 0, 0, 0, time.UTC).Day(); day > last {
+		day = last
+	}
+	h, min, sec := t.Clock()
+	return Date(time.Date(y, m+time.Month(n), day, h, min, sec, t.Nanosecond(), t.Location()))
+}
+
+// DaysBetween returns the number of calendar days from d to e, which is
+// negative if e is before d.
+func (d Date) DaysBetween(e Date) int {
+	return int(civilDays(e.midnightUTC(), d.midnightUTC()))
+}
+
+// Compare returns -1, 0 or 1 as the calendar day of d is before, the same as
+// or after the calendar day of e. The time of day and location are ignored.
+func (d Date) Compare(e Date) int {
+	switch n := d.DaysBetween(e); {
+	case n > 0:
+		return -1
+	case n < 0:
+		return 1
+	}
+	return 0
+}
+
+// Before reports whether the calendar day of d is before that of e.
+func (d Date) Before(e Date) bool {
+	return d.Compare(e) < 0
+}
+
+// After reports whether the calendar day of d is after that of e.
+func (d Date) After(e Date) bool {
+	return d.Compare(e) > 0
+}
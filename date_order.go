@@ -0,0 +1,130 @@
+// Copyright 2019 Miles Barr <milesbarr2@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marshaler
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// A DateOrder selects how a FlexibleTime interprets numeric dates such as
+// "03/04/2019", where the day and month could be in either order.
+type DateOrder int
+
+const (
+	// RejectAmbiguous accepts numeric dates only when the order is clear,
+	// as in "13/04/2019" or "04/04/2019", and rejects the rest.
+	RejectAmbiguous DateOrder = iota
+
+	// MonthFirst reads ambiguous numeric dates month first, as in the
+	// United States.
+	MonthFirst
+
+	// DayFirst reads ambiguous numeric dates day first, as in Europe.
+	DayFirst
+)
+
+// errAmbiguousDate is reported for a numeric date whose day and month could
+// be in either order under the RejectAmbiguous policy.
+var errAmbiguousDate = errors.New("ambiguous numeric date")
+
+// numericDateTimes are the times of day that may follow a numeric date.
+var numericDateTimes = [...]string{
+	"",
+	" 15:04",
+	" 15:04:05",
+	" 3:04 PM",
+	" 3:04:05 PM",
+	"T15:04:05",
+}
+
+// DateOrder returns the order in which p reads ambiguous numeric dates.
+func (p *TimeParser) DateOrder() DateOrder {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.order
+}
+
+// SetDateOrder sets the order in which p reads ambiguous numeric dates.
+func (p *TimeParser) SetDateOrder(order DateOrder) {
+	p.mu.Lock()
+	p.order = order
+	p.mu.Unlock()
+}
+
+// parseNumericDate parses a date written as three numbers separated by '/',
+// '-' or '.', with the year last, such as "03/04/2019" or "3-4-19 15:04". A
+// '.' separator is always read day first.
+func parseNumericDate(s string, loc *time.Location, order DateOrder) (time.Time, error) {
+	sep, a, b, ok := splitNumericDate(s)
+	if !ok {
+		return time.Time{}, errors.New("not a numeric date")
+	}
+	dayFirst := sep == '.'
+	switch {
+	case dayFirst:
+	case a > 12 && b > 12:
+		return time.Time{}, errors.New("invalid numeric date")
+	case a > 12:
+		dayFirst = true
+	case b > 12 || a == b:
+	case order == DayFirst:
+		dayFirst = true
+	case order != MonthFirst:
+		return time.Time{}, errAmbiguousDate
+	}
+	date := "1" + string(sep) + "2" + string(sep)
+	if dayFirst {
+		date = "2" + string(sep) + "1" + string(sep)
+	}
+	var err error
+	for _, year := range []string{"2006", "06"} {
+		for _, tod := range numericDateTimes {
+			var t time.Time
+			if t, err = parseTime(s, loc, date+year+tod); err == nil {
+				return t, nil
+			}
+		}
+	}
+	return time.Time{}, err
+}
+
+// splitNumericDate reports whether s starts with a numeric date and returns
+// its separator and its first two numbers.
+func splitNumericDate(s string) (sep byte, a, b int, ok bool) {
+	i := 0
+	number := func() (int, bool) {
+		n, start := 0, i
+		for i < len(s) && i-start < 4 && s[i] >= '0' && s[i] <= '9' {
+			n = n*10 + int(s[i]-'0')
+			i++
+		}
+		return n, i > start
+	}
+	if a, ok = number(); !ok || i > 2 || i == len(s) {
+		return 0, 0, 0, false
+	}
+	sep = s[i]
+	if !strings.ContainsRune("/-.", rune(sep)) {
+		return 0, 0, 0, false
+	}
+	i++
+	start := i
+	if b, ok = number(); !ok || i-start > 2 || i == len(s) || s[i] != sep {
+		return 0, 0, 0, false
+	}
+	return sep, a, b, true
+}
@@ -0,0 +1,344 @@
+// Copyright 2019 Miles Barr <milesbarr2@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marshaler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A DateRange is a range of days, such as a reporting period. It can be
+// unmarshaled from an ISO 8601 interval, such as "2019-01-01/2019-02-01" or
+// "2019-01-01/P1M", whose end is exclusive, or from a range such as
+// "2019-01-01..2019-01-31", whose end is inclusive. Either bound may be
+// omitted, or written as "..", for an open-ended range, as in
+// "2019-01-01/..". In JSON a DateRange may also be an object with "start"
+// and "end" fields, whose end is inclusive, so that {"start": "2019-01-01",
+// "end": "2019-01-31"} is January 2019.
+//
+// A DateRange is marshaled as an interval if its end is exclusive and as a
+// range otherwise, and the zero DateRange as an empty string.
+type DateRange struct {
+	// Start is the first day of the range, or the zero Date if the range
+	// has no start.
+	Start Date
+
+	// End is the end of the range, or the zero Date if the range has no
+	// end. End is in the range only if InclusiveEnd is set.
+	End          Date
+	InclusiveEnd bool
+}
+
+// ParseDateRange parses s as a DateRange.
+func ParseDateRange(s string) (DateRange, error) {
+	var r DateRange
+	start, end, inclusive, ok := splitInterval(strings.TrimSpace(s), func(side string) bool {
+		var d Date
+		return d.Set(side) == nil
+	})
+	if ok {
+		ok = r.setBounds(start, end, inclusive) == nil
+	}
+	if !ok {
+		return DateRange{}, fmt.Errorf("marshaler.ParseDateRange: cannot parse \"%s\"", s)
+	}
+	return r, nil
+}
+
+// SetBounds sets r from separate start and end strings, either of which may
+// be empty for an open-ended range or an ISO 8601 period relative to the
+// other, such as "P1M".
+func (r *DateRange) SetBounds(start, end string, inclusiveEnd bool) error {
+	start, end = strings.TrimSpace(start), strings.TrimSpace(end)
+	if err := r.setBounds(start, end, inclusiveEnd); err != nil {
+		return fmt.Errorf("marshaler.DateRange.SetBounds: cannot parse \"%s\", \"%s\"", start, end)
+	}
+	return nil
+}
+
+func (r *DateRange) setBounds(start, end string, inclusiveEnd bool) error {
+	v := DateRange{InclusiveEnd: inclusiveEnd}
+	startPeriod, startIsPeriod := parsePeriod(start)
+	endPeriod, endIsPeriod := parsePeriod(end)
+	if startIsPeriod && endIsPeriod || startPeriod.d != 0 || endPeriod.d != 0 {
+		return errInterval
+	}
+	if !startIsPeriod {
+		if err := v.Start.Set(start); err != nil {
+			return err
+		}
+	}
+	if !endIsPeriod {
+		if err := v.End.Set(end); err != nil {
+			return err
+		}
+	}
+	switch {
+	case startIsPeriod:
+		if v.End.isZero() {
+			return errInterval
+		}
+		v.Start = Date(startPeriod.addTo(time.Time(v.End), -1))
+	case endIsPeriod:
+		if v.Start.isZero() {
+			return errInterval
+		}
+		v.End = Date(endPeriod.addTo(time.Time(v.Start), 1))
+	}
+	*r = v
+	return nil
+}
+
+// isZero reports whether d is the zero Date.
+func (d Date) isZero() bool {
+	return time.Time(d).IsZero()
+}
+
+// last returns the last day of r and reports whether r has an end.
+func (r DateRange) last() (Date, bool) {
+	if r.End.isZero() {
+		return Date{}, false
+	}
+	if r.InclusiveEnd {
+		return r.End, true
+	}
+	return r.End.AddDays(-1), true
+}
+
+// Contains reports whether d is in r.
+func (r DateRange) Contains(d Date) bool {
+	if !r.Start.isZero() && d.Before(r.Start) {
+		return false
+	}
+	last, ok := r.last()
+	return !ok || !d.After(last)
+}
+
+// Intersect returns the days that are in both r and s, with the end
+// inclusivity of r. It reports false if there are none.
+func (r DateRange) Intersect(s DateRange) (DateRange, bool) {
+	start := r.Start
+	if start.isZero() || !s.Start.isZero() && s.Start.After(start) {
+		start = s.Start
+	}
+	last, ok := r.last()
+	if sLast, sOK := s.last(); sOK && (!ok || sLast.Before(last)) {
+		last, ok = sLast, true
+	}
+	v := DateRange{Start: start, InclusiveEnd: r.InclusiveEnd}
+	if ok {
+		if !start.isZero() && last.Before(start) {
+			return DateRange{}, false
+		}
+		v.End = last
+		if !v.InclusiveEnd {
+			v.End = last.AddDays(1)
+		}
+	}
+	return v, true
+}
+
+// Overlaps reports whether r and s have any days in common.
+func (r DateRange) Overlaps(s DateRange) bool {
+	_, ok := r.Intersect(s)
+	return ok
+}
+
+// ForEachDay calls f for each day of r in order until f returns false. If r
+// has no start, f is not called, and if r has no end, ForEachDay continues
+// until f returns false.
+func (r DateRange) ForEachDay(f func(Date) bool) {
+	if r.Start.isZero() {
+		return
+	}
+	last, ok := r.last()
+	for d := r.Start; !ok || !d.After(last); d = d.AddDays(1) {
+		if !f(d) {
+			return
+		}
+	}
+}
+
+// String implements the flag.Value interface.
+func (r DateRange) String() string {
+	var start, end string
+	if !r.Start.isZero() {
+		start = r.Start.String()
+	}
+	if !r.End.isZero() {
+		end = r.End.String()
+	}
+	return joinInterval(start, end, r.InclusiveEnd)
+}
+
+// Set implements the flag.Value interface.
+func (r *DateRange) Set(s string) error {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	v, err := ParseDateRange(s)
+	if err != nil {
+		return fmt.Errorf("marshaler.DateRange.Set: cannot parse \"%s\"", s)
+	}
+	*r = v
+	return nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (r DateRange) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (r *DateRange) UnmarshalText(text []byte) error {
+	return r.Set(string(text))
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. The value may be
+// a JSON string or an object with "start" and "end" fields, whose end is
+// inclusive.
+func (r *DateRange) UnmarshalJSON(b []byte) error {
+	var bounds struct{ Start, End string }
+	if err := json.Unmarshal(b, &bounds); err == nil {
+		if bounds.Start == "" && bounds.End == "" {
+			return nil
+		}
+		return r.SetBounds(bounds.Start, bounds.End, true)
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		b = []byte(s)
+	}
+	return r.UnmarshalText(b)
+}
+
+// errInterval is reported for an invalid interval.
+var errInterval = errors.New("invalid interval")
+
+// splitInterval splits the interval s into its start and end, either of
+// which may be empty for an open-ended interval, and reports whether the end
+// is inclusive. An interval separated by "/" has an exclusive end and one
+// separated by ".." an inclusive end. Since "/" may also appear within a
+// bound, each "/" is tried until valid accepts both bounds.
+func splitInterval(s string, valid func(string) bool) (start, end string, inclusive, ok bool) {
+	validBound := func(b string) bool {
+		_, isPeriod := parsePeriod(b)
+		return b == "" || isPeriod || valid(b)
+	}
+	switch {
+	case strings.HasPrefix(s, "../"):
+		start, end = "", s[3:]
+	case strings.HasSuffix(s, "/.."):
+		start, end = s[:len(s)-3], ""
+	case strings.Contains(s, ".."):
+		i := strings.Index(s, "..")
+		start, end, inclusive = s[:i], s[i+2:], true
+	default:
+		for i := 0; i < len(s); i++ {
+			if s[i] == '/' && validBound(s[:i]) && validBound(s[i+1:]) {
+				start, end = s[:i], s[i+1:]
+				ok = start != "" || end != ""
+				return start, end, false, ok
+			}
+		}
+		return "", "", false, false
+	}
+	start, end = strings.TrimSpace(start), strings.TrimSpace(end)
+	return start, end, inclusive, (start != "" || end != "" || s == "..") && validBound(start) && validBound(end)
+}
+
+// joinInterval formats an interval with the given bounds. An interval with
+// neither bound is formatted as an empty string, or as ".." if its end is
+// inclusive, so that it can be parsed back.
+func joinInterval(start, end string, inclusive bool) string {
+	switch {
+	case inclusive:
+		return start + ".." + end
+	case start == "" && end == "":
+		return ""
+	case start == "":
+		return "../" + end
+	case end == "":
+		return start + "/.."
+	}
+	return start + "/" + end
+}
+
+// A period is an ISO 8601 duration with calendar years, months and days.
+type period struct {
+	years, months, days int
+	d                   time.Duration
+}
+
+// parsePeriod parses an ISO 8601 duration, such as "P1M" or "P1DT12H", whose
+// years, months, weeks and days are whole numbers.
+func parsePeriod(s string) (period, bool) {
+	var p period
+	if !strings.HasPrefix(s, "P") || s == "P" {
+		return period{}, false
+	}
+	s = s[1:]
+	timePart := ""
+	if i := strings.IndexByte(s, 'T'); i >= 0 {
+		s, timePart = s[:i], s[i:]
+	}
+	designators := "YMWD"
+	for s != "" {
+		i := 0
+		for i < len(s) && isDigitByte(s[i]) {
+			i++
+		}
+		if i == 0 || i == len(s) || i > 9 {
+			return period{}, false
+		}
+		j := strings.IndexByte(designators, s[i])
+		if j < 0 {
+			return period{}, false
+		}
+		n, _ := strconv.Atoi(s[:i])
+		switch designators[j] {
+		case 'Y':
+			p.years = n
+		case 'M':
+			p.months = n
+		case 'W':
+			p.days += 7 * n
+		case 'D':
+			p.days += n
+		}
+		designators, s = designators[j+1:], s[i+1:]
+	}
+	if timePart != "" {
+		ns, err := parseISODuration("P" + timePart)
+		if err != nil || !ns.IsInt64() {
+			return period{}, false
+		}
+		p.d = time.Duration(ns.Int64())
+	}
+	return p, true
+}
+
+// addTo returns t plus the period multiplied by sign, which is 1 or -1.
+// Years and months are added first, moving the day to the end of the month
+// as Date.AddMonths does, so that "2024-01-31/P1M" ends on 2024-02-29.
+func (p period) addTo(t time.Time, sign int) time.Time {
+	t = time.Time(Date(t).AddMonths(sign * (12*p.years + p.months)))
+	return t.AddDate(0, 0, sign*p.days).Add(time.Duration(sign) * p.d)
+}
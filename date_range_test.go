@@ -0,0 +1,95 @@
+// Copyright 2019 Miles Barr <milesbarr2@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marshaler_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jadefox10200/marshaler"
+)
+
+func TestParseDateRange(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"2019-01-01/2019-02-01", "2019-01-01/2019-02-01"},
+		{"2019-01-01..2019-01-31", "2019-01-01..2019-01-31"},
+		{"2019-01-01/..", "2019-01-01/.."},
+		{"../2019-02-01", "../2019-02-01"},
+		{"2019-01-01..", "2019-01-01.."},
+		{"..2019-01-31", "..2019-01-31"},
+		{"..", ".."},
+		{"2019-01-01/P1M", "2019-01-01/2019-02-01"},
+		{"P1M/2019-02-01", "2019-01-01/2019-02-01"},
+		// Months are clamped to the end of the month.
+		{"2024-01-31/P1M", "2024-01-31/2024-02-29"},
+		{"2023-01-31/P1M", "2023-01-31/2023-02-28"},
+		{"2024-02-29/P1Y", "2024-02-29/2025-02-28"},
+		{"2024-01-31/P1M1D", "2024-01-31/2024-03-01"},
+		{"P1M/2024-03-31", "2024-02-29/2024-03-31"},
+		{"2019-01-01/P2W", "2019-01-01/2019-01-15"},
+	}
+	for _, tt := range tests {
+		r, err := marshaler.ParseDateRange(tt.in)
+		if err != nil {
+			t.Errorf("ParseDateRange(%q): %v", tt.in, err)
+			continue
+		}
+		if got := r.String(); got != tt.want {
+			t.Errorf("ParseDateRange(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+	for _, in := range []string{"../", "/", "2019-01-01", "P1M/P1M", "2019-01-01/PT1H", "2019-13-01/.."} {
+		if r, err := marshaler.ParseDateRange(in); err == nil {
+			t.Errorf("ParseDateRange(%q) = %s, want error", in, r)
+		}
+	}
+}
+
+func TestDateRangeRoundTrip(t *testing.T) {
+	jan := date(2019, 1, 1)
+	feb := date(2019, 2, 1)
+	for _, r := range []marshaler.DateRange{
+		{},
+		{InclusiveEnd: true},
+		{Start: jan},
+		{Start: jan, InclusiveEnd: true},
+		{End: feb},
+		{End: feb, InclusiveEnd: true},
+		{Start: jan, End: feb},
+		{Start: jan, End: feb, InclusiveEnd: true},
+	} {
+		text, err := r.MarshalText()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got marshaler.DateRange
+		if err := got.UnmarshalText(text); err != nil || got != r {
+			t.Errorf("UnmarshalText(%q) = %+v, %v; want %+v", text, got, err, r)
+		}
+		b, err := json.Marshal(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = marshaler.DateRange{}
+		if err := json.Unmarshal(b, &got); err != nil || got != r {
+			t.Errorf("json.Unmarshal(%s) = %+v, %v; want %+v", b, got, err, r)
+		}
+	}
+	if b, _ := json.Marshal(marshaler.DateRange{}); string(b) != `""` {
+		t.Errorf("json.Marshal of the zero DateRange = %s", b)
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright 2019 Miles Barr <milesbarr2@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marshaler_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jadefox10200/marshaler"
+)
+
+// date returns the Date of the given day in UTC.
+func date(y int, m time.Month, d int) marshaler.Date {
+	return marshaler.Date(time.Date(y, m, d, 0, 0, 0, 0, time.UTC))
+}
+
+func TestDateAddDays(t *testing.T) {
+	tests := []struct {
+		d    marshaler.Date
+		n    int
+		want string
+	}{
+		{date(2019, 7, 4), 0, "2019-07-04"},
+		{date(2019, 7, 4), 1, "2019-07-05"},
+		{date(2019, 7, 4), -4, "2019-06-30"},
+		{date(2019, 12, 31), 1, "2020-01-01"},
+		{date(2020, 2, 28), 1, "2020-02-29"},
+		{date(2021, 2, 28), 1, "2021-03-01"},
+		{date(2019, 7, 4), 366, "2020-07-04"},
+	}
+	for _, tt := range tests {
+		if got := tt.d.AddDays(tt.n).String(); got != tt.want {
+			t.Errorf("%s.AddDays(%d) = %s, want %s", tt.d, tt.n, got, tt.want)
+		}
+	}
+	// Days are calendar days across a daylight saving time change.
+	ny := mustLoadLocation(t, "America/New_York")
+	d := marshaler.Date(time.Date(2021, 3, 13, 0, 0, 0, 0, ny)).AddDays(1)
+	if got := time.Time(d).Format(time.RFC3339); got != "2021-03-14T00:00:00-05:00" {
+		t.Errorf("AddDays across DST = %s", got)
+	}
+}
+
+func TestDateAddMonths(t *testing.T) {
+	tests := []struct {
+		d    marshaler.Date
+		n    int
+		want string
+	}{
+		{date(2019, 7, 4), 1, "2019-08-04"},
+		{date(2019, 1, 31), 1, "2019-02-28"},
+		{date(2020, 1, 31), 1, "2020-02-29"},
+		{date(2019, 3, 31), -1, "2019-02-28"},
+		{date(2019, 5, 31), 1, "2019-06-30"},
+		{date(2019, 8, 31), -6, "2019-02-28"},
+		{date(2019, 12, 31), 2, "2020-02-29"},
+		{date(2020, 2, 29), 12, "2021-02-28"},
+		{date(2020, 2, 29), 48, "2024-02-29"},
+		{date(2019, 1, 15), -13, "2017-12-15"},
+		{date(2019, 10, 31), 0, "2019-10-31"},
+	}
+	for _, tt := range tests {
+		if got := tt.d.AddMonths(tt.n).String(); got != tt.want {
+			t.Errorf("%s.AddMonths(%d) = %s, want %s", tt.d, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestDateDaysBetweenAndCompare(t *testing.T) {
+	tokyo := mustLoadLocation(t, "Asia/Tokyo")
+	tests := []struct {
+		d, e marshaler.Date
+		days int
+	}{
+		{date(2019, 7, 4), date(2019, 7, 4), 0},
+		{date(2019, 7, 4), date(2019, 7, 5), 1},
+		{date(2019, 7, 5), date(2019, 7, 4), -1},
+		{date(2019, 1, 1), date(2020, 1, 1), 365},
+		{date(2020, 1, 1), date(2021, 1, 1), 366},
+		{date(1969, 12, 31), date(1970, 1, 2), 2},
+		// Only the calendar day counts, not the time of day or location.
+		{marshaler.Date(time.Date(2019, 7, 4, 23, 0, 0, 0, tokyo)), marshaler.Date(time.Date(2019, 7, 4, 1, 0, 0, 0, time.UTC)), 0},
+		{marshaler.Date(time.Date(2019, 7, 4, 23, 59, 0, 0, time.UTC)), marshaler.Date(time.Date(2019, 7, 5, 0, 1, 0, 0, time.UTC)), 1},
+	}
+	for _, tt := range tests {
+		if got := tt.d.DaysBetween(tt.e); got != tt.days {
+			t.Errorf("%s.DaysBetween(%s) = %d, want %d", tt.d, tt.e, got, tt.days)
+		}
+		want := 0
+		switch {
+		case tt.days > 0:
+			want = -1
+		case tt.days < 0:
+			want = 1
+		}
+		if got := tt.d.Compare(tt.e); got != want {
+			t.Errorf("%s.Compare(%s) = %d, want %d", tt.d, tt.e, got, want)
+		}
+		if got := tt.d.Before(tt.e); got != (want < 0) {
+			t.Errorf("%s.Before(%s) = %v", tt.d, tt.e, got)
+		}
+		if got := tt.d.After(tt.e); got != (want > 0) {
+			t.Errorf("%s.After(%s) = %v", tt.d, tt.e, got)
+		}
+	}
+}
@@ -24,18 +24,28 @@ import (
 // in YYYY-MM-DD HH-MM-SS format.
 type DateTime time.Time
 
+// dateTimeLayout is the layout of a DateTime.
+const dateTimeLayout = "2006-01-02 15:04:05"
+
 // Strings implements the flag.Value interface.
 func (dt DateTime) String() string {
-	return time.Time(dt).Format("2006-01-02 15:04:05")
+	return time.Time(dt).Format(dateTimeLayout)
 }
 
-// Set implements the flag.Value interface.
+// Set implements the flag.Value interface. Strings without an explicit time
+// zone are interpreted in the location of DefaultTimeParser.
 func (dt *DateTime) Set(s string) error {
+	return dt.SetInLocation(s, DefaultTimeParser.Location())
+}
+
+// SetInLocation is like Set but interprets strings without an explicit time
+// zone in loc.
+func (dt *DateTime) SetInLocation(s string, loc *time.Location) error {
 	s = strings.TrimSpace(s)
 	if s == "" {
 		return nil
 	}
-	t, err := time.Parse("2006-01-02 15:04:05", s)
+	t, err := parseTime(s, loc, dateTimeLayout)
 	if err != nil {
 		return fmt.Errorf("marshaler.DateTime.Set: cannot parse \"%s\"", s)
 	}
@@ -0,0 +1,286 @@
+// Copyright 2019 Miles Barr <milesbarr2@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marshaler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// ErrDivisionByZero is reported when a Decimal is divided by zero.
+var ErrDivisionByZero = errors.New("division by zero")
+
+// A Decimal is an arbitrary-precision decimal number, such as a price, that
+// can be unmarshaled from a string or a JSON number without loss of
+// precision. Its value is an unscaled integer multiplied by 10^-scale, so
+// "1.50" has the unscaled value 150 and a scale of 2. The zero value is 0.
+//
+// Decimals are immutable: arithmetic methods return new values.
+type Decimal struct {
+	unscaled *big.Int
+	scale    int32
+}
+
+// NewDecimal returns the Decimal unscaled * 10^-scale.
+func NewDecimal(unscaled int64, scale int32) Decimal {
+	return newDecimal(big.NewInt(unscaled), scale)
+}
+
+// newDecimal returns the Decimal u * 10^-scale, which takes ownership of u.
+// A negative scale is folded into the unscaled value.
+func newDecimal(u *big.Int, scale int32) Decimal {
+	if scale < 0 {
+		return Decimal{unscaled: u.Mul(u, pow10(-scale))}
+	}
+	return Decimal{unscaled: u, scale: scale}
+}
+
+// ParseDecimal parses s as a Decimal according to DefaultNumberFormat.
+func ParseDecimal(s string) (Decimal, error) {
+	return DefaultNumberFormat.ParseDecimal(s)
+}
+
+// ParseDecimal parses s as a Decimal according to nf. The scale of the result
+// is the number of digits after the decimal point, after any exponent or
+// magnitude suffix has been applied.
+func (nf NumberFormat) ParseDecimal(s string) (Decimal, error) {
+	n := strings.TrimSpace(s)
+	if nf.Accounting {
+		var ok bool
+		if n, _, ok = nf.stripAccounting(n); !ok {
+			return Decimal{}, numError("ParseDecimal", s, strconv.ErrSyntax)
+		}
+	}
+	n, err := nf.normalize(n)
+	if err != nil {
+		return Decimal{}, numError("ParseDecimal", s, err)
+	}
+	neg, digits, point, ok := splitDecimal(n)
+	if !ok {
+		return Decimal{}, numError("ParseDecimal", s, strconv.ErrSyntax)
+	}
+	if point > len(digits) {
+		digits += strings.Repeat("0", point-len(digits))
+	}
+	u, _ := new(big.Int).SetString(digits, 10)
+	if neg {
+		u.Neg(u)
+	}
+	return Decimal{unscaled: u, scale: int32(len(digits) - point)}, nil
+}
+
+// int returns the unscaled value of d.
+func (d Decimal) int() *big.Int {
+	if d.unscaled == nil {
+		return new(big.Int)
+	}
+	return d.unscaled
+}
+
+// rescale returns the unscaled value of d at a scale of at least d.scale.
+func (d Decimal) rescale(scale int32) *big.Int {
+	if scale <= d.scale {
+		return d.int()
+	}
+	return new(big.Int).Mul(d.int(), pow10(scale-d.scale))
+}
+
+// Scale returns the number of digits after the decimal point in d.
+func (d Decimal) Scale() int32 {
+	return d.scale
+}
+
+// Sign returns -1, 0 or 1 as d is negative, zero or positive.
+func (d Decimal) Sign() int {
+	return d.int().Sign()
+}
+
+// Cmp returns -1, 0 or 1 as d is less than, equal to or greater than e.
+// Decimals with different scales but the same value, such as 1.5 and 1.50,
+// compare as equal.
+func (d Decimal) Cmp(e Decimal) int {
+	scale := maxScale(d, e)
+	return d.rescale(scale).Cmp(e.rescale(scale))
+}
+
+// Neg returns -d.
+func (d Decimal) Neg() Decimal {
+	return Decimal{unscaled: new(big.Int).Neg(d.int()), scale: d.scale}
+}
+
+// Abs returns the absolute value of d.
+func (d Decimal) Abs() Decimal {
+	return Decimal{unscaled: new(big.Int).Abs(d.int()), scale: d.scale}
+}
+
+// Add returns d + e. The scale of the result is the larger of the two
+// scales.
+func (d Decimal) Add(e Decimal) Decimal {
+	scale := maxScale(d, e)
+	return Decimal{unscaled: new(big.Int).Add(d.rescale(scale), e.rescale(scale)), scale: scale}
+}
+
+// Sub returns d - e. The scale of the result is the larger of the two
+// scales.
+func (d Decimal) Sub(e Decimal) Decimal {
+	scale := maxScale(d, e)
+	return Decimal{unscaled: new(big.Int).Sub(d.rescale(scale), e.rescale(scale)), scale: scale}
+}
+
+// Mul returns d * e. The scale of the result is the sum of the two scales.
+func (d Decimal) Mul(e Decimal) Decimal {
+	return Decimal{unscaled: new(big.Int).Mul(d.int(), e.int()), scale: d.scale + e.scale}
+}
+
+// Div returns d / e rounded to the given scale according to mode. A negative
+// scale rounds to a multiple of a power of ten, such as 100 for -2.
+func (d Decimal) Div(e Decimal, scale int32, mode RoundingMode) (Decimal, error) {
+	if e.Sign() == 0 {
+		return Decimal{}, ErrDivisionByZero
+	}
+	// d / e = (d.unscaled / e.unscaled) * 10^(e.scale-d.scale), so the
+	// unscaled result is d.unscaled * 10^(scale+e.scale-d.scale) / e.unscaled.
+	num, den := new(big.Int).Set(d.int()), new(big.Int).Set(e.int())
+	if shift := scale + e.scale - d.scale; shift >= 0 {
+		num.Mul(num, pow10(shift))
+	} else {
+		den.Mul(den, pow10(-shift))
+	}
+	q, err := mode.quo(num, den)
+	if err != nil {
+		return Decimal{}, err
+	}
+	return newDecimal(q, scale), nil
+}
+
+// Round returns d rounded to the given number of digits after the decimal
+// point according to mode. If scale is larger than the scale of d, trailing
+// zeros are added. A negative scale rounds to a multiple of a power of ten.
+func (d Decimal) Round(scale int32, mode RoundingMode) (Decimal, error) {
+	if scale >= d.scale {
+		return Decimal{unscaled: d.rescale(scale), scale: scale}, nil
+	}
+	q, err := mode.quo(d.int(), pow10(d.scale-scale))
+	if err != nil {
+		return Decimal{}, err
+	}
+	return newDecimal(q, scale), nil
+}
+
+// Float64 returns the float64 nearest to d.
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// String implements the flag.Value interface. It formats d in decimal
+// notation with exactly Scale digits after the decimal point.
+func (d Decimal) String() string {
+	u := d.int()
+	digits := new(big.Int).Abs(u).String()
+	if d.scale > 0 {
+		if n := int(d.scale) + 1 - len(digits); n > 0 {
+			digits = strings.Repeat("0", n) + digits
+		}
+		i := len(digits) - int(d.scale)
+		digits = digits[:i] + "." + digits[i:]
+	}
+	if u.Sign() < 0 {
+		return "-" + digits
+	}
+	return digits
+}
+
+// Set implements the flag.Value interface.
+func (d *Decimal) Set(s string) error {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	v, err := DefaultNumberFormat.ParseDecimal(s)
+	if err != nil {
+		return fmt.Errorf("marshaler.Decimal.Set: cannot parse \"%s\"", s)
+	}
+	*d = v
+	return nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (d Decimal) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (d *Decimal) UnmarshalText(text []byte) error {
+	return d.Set(string(text))
+}
+
+// MarshalJSON implements the json.Marshaler interface. The value is written
+// as a JSON number, or as a JSON string if DefaultNumberFormat.JSONString is
+// set.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return DefaultNumberFormat.marshalNumber(d.String()), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (d *Decimal) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		b = []byte(s)
+	}
+	return d.UnmarshalText(b)
+}
+
+// quo returns num / den rounded to an integer according to m.
+func (m RoundingMode) quo(num, den *big.Int) (*big.Int, error) {
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+	if r.Sign() == 0 {
+		return q, nil
+	}
+	// Compare the remainder with half of the divisor.
+	r2 := new(big.Int).Abs(r)
+	r2.Lsh(r2, 1)
+	cmp := r2.Cmp(new(big.Int).Abs(den))
+	neg := num.Sign() != den.Sign()
+	up, err := m.roundUp(neg, byte('0'+q.Bit(0)), cmp, false)
+	if err != nil {
+		return nil, err
+	}
+	if up {
+		if neg {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return q, nil
+}
+
+// pow10 returns 10^n.
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// maxScale returns the larger of the scales of d and e.
+func maxScale(d, e Decimal) int32 {
+	if d.scale > e.scale {
+		return d.scale
+	}
+	return e.scale
+}
@@ -15,8 +15,11 @@
 package marshaler_test
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
+	"math/big"
+	"time"
 
 	"github.com/jadefox10200/marshaler"
 )
@@ -29,3 +32,283 @@ func ExampleCommaSeparatedString() {
 	fmt.Println(css.String())
 	// Output: foo,bar
 }
+
+func ExampleNumberFormat() {
+	nf := marshaler.NumberFormat{Locale: marshaler.LocaleAuto}
+	for _, s := range []string{"1,234.56", "1.234,56", "1 234"} {
+		f, err := nf.ParseFloat(s, 64)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(f)
+	}
+	// Output:
+	// 1234.56
+	// 1234.56
+	// 1234
+}
+
+func ExampleDecimal() {
+	var price marshaler.Decimal
+	if err := price.UnmarshalJSON([]byte(`"19.99"`)); err != nil {
+		log.Fatal(err)
+	}
+	total := price.Mul(marshaler.NewDecimal(3, 0))
+	each, err := total.Div(marshaler.NewDecimal(7, 0), 2, marshaler.RoundHalfEven)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(total, each)
+	// Output: 59.97 8.57
+}
+
+func ExampleAmount() {
+	var a marshaler.Amount
+	if err := json.Unmarshal([]byte(`"($1,234.50)"`), &a); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(a.Value, a.Currency)
+	b, err := json.Marshal(a)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(string(b))
+	// Output:
+	// -1234.5 $
+	// "-1234.5 $"
+}
+
+func ExampleDateRange() {
+	// The end of a DateRange object is inclusive.
+	var r marshaler.DateRange
+	if err := json.Unmarshal([]byte(`{"start": "2019-01-01", "end": "2019-01-31"}`), &r); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(r, r.Contains(marshaler.Date(time.Date(2019, 1, 31, 0, 0, 0, 0, time.UTC))))
+	// Output: 2019-01-01..2019-01-31 true
+}
+
+func ExampleTimeInterval() {
+	// The end of a TimeInterval object is exclusive.
+	var ti marshaler.TimeInterval
+	if err := json.Unmarshal([]byte(`{"start": "2019-01-01T09:00:00Z", "end": "2019-01-01T17:00:00Z"}`), &ti); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(ti, ti.Duration())
+	// Output: 2019-01-01T09:00:00Z/2019-01-01T17:00:00Z 8h0m0s
+}
+
+func ExampleSuffixMode() {
+	nf := marshaler.NumberFormat{Suffixes: marshaler.SuffixShortScale}
+	for _, s := range []string{"1.5k", "2.5MM", "3 billion"} {
+		n, err := nf.ParseInt(s, 64)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(n)
+	}
+	si := marshaler.NumberFormat{Suffixes: marshaler.SuffixSI}
+	f, err := si.ParseFloat("4.7u", 64)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(f)
+	// Output:
+	// 1500
+	// 2500000
+	// 3000000000
+	// 4.7e-06
+}
+
+func ExampleNumberFormat_detectBase() {
+	nf := marshaler.NumberFormat{DetectBase: true}
+	for _, s := range []string{"0x1F", "0o17", "0b1010", "1_000_000", "017"} {
+		n, err := nf.ParseInt(s, 64)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(n)
+	}
+	// Output:
+	// 31
+	// 15
+	// 10
+	// 1000000
+	// 17
+}
+
+func ExampleRobustBool() {
+	for _, s := range []string{"yes", "No", "on", "OFF", "Y", "0"} {
+		var b marshaler.RobustBool
+		if err := b.Set(s); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(s, b)
+	}
+	// Output:
+	// yes true
+	// No false
+	// on true
+	// OFF false
+	// Y true
+	// 0 false
+}
+
+func ExampleRobustBigInt() {
+	var v struct {
+		Supply *marshaler.RobustBigInt
+		Price  *marshaler.RobustBigFloat
+	}
+	err := json.Unmarshal([]byte(`{"Supply": "123456789012345678901234567890", "Price": 0.000000000000000001}`), &v)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(new(big.Int).Mul(v.Supply.Int(), big.NewInt(2)))
+	fmt.Println(v.Price)
+	// Output:
+	// 246913578024691357802469135780
+	// 1e-18
+}
+
+func ExampleSentinelPolicy() {
+	nf := marshaler.NumberFormat{SentinelPolicy: marshaler.SentinelNaN}
+	for _, s := range []string{"N/A", "--", "1.5"} {
+		f, err := nf.ParseFloat(s, 64)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(f)
+	}
+	nf.SentinelPolicy = marshaler.SentinelError
+	_, err := nf.ParseFloat("N/A", 64)
+	fmt.Println(err != nil)
+	// Output:
+	// NaN
+	// NaN
+	// 1.5
+	// true
+}
+
+func ExampleNumberFormat_FormatFloat() {
+	var nf marshaler.NumberFormat
+	a, b := 0.1, 0.2
+	fmt.Println(nf.FormatFloat(a+b, 64))
+	fmt.Println(nf.FormatFloat(1e21, 64))
+	fmt.Println(nf.FormatFloat(float64(float32(0.1)), 32))
+	nf.Fixed, nf.Precision = true, 2
+	fmt.Println(nf.FormatFloat(3.14159, 64))
+	// Output:
+	// 0.30000000000000004
+	// 1e+21
+	// 0.1
+	// 3.14
+}
+
+func ExampleTimeParser_AddLayout() {
+	p := &marshaler.TimeParser{}
+	// The timestamp format of Apache access logs.
+	p.AddLayout("02/Jan/2006:15:04:05 -0700")
+	ft, err := p.ParseFlexibleTime("17/Jul/2019:09:30:00 +0200")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(ft.Format(time.RFC3339))
+	// Output: 2019-07-17T09:30:00+02:00
+}
+
+func ExampleFlexibleTime() {
+	for _, s := range []string{
+		"2019-07-17T09:30:00+02:00",
+		"Wed, 17 Jul 2019 09:30:00 GMT",
+		"Jul 17, 2019 9:30 AM",
+		"17-Jul-2019",
+		"20190717T093000Z",
+	} {
+		var ft marshaler.FlexibleTime
+		if err := ft.Set(s); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(ft.Format(time.RFC3339))
+	}
+	// Output:
+	// 2019-07-17T09:30:00+02:00
+	// 2019-07-17T09:30:00Z
+	// 2019-07-17T09:30:00Z
+	// 2019-07-17T00:00:00Z
+	// 2019-07-17T09:30:00Z
+}
+
+func ExampleTimeParser_SetDateOrder() {
+	p := &marshaler.TimeParser{}
+	if _, err := p.ParseFlexibleTime("03/04/2019"); err != nil {
+		fmt.Println("ambiguous")
+	}
+	p.SetDateOrder(marshaler.DayFirst)
+	ft, err := p.ParseFlexibleTime("03/04/2019")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(ft.Format("2 January 2006"))
+	// Output:
+	// ambiguous
+	// 3 April 2019
+}
+
+func ExampleAutoTimestamp() {
+	// The unit is detected from the magnitude of the number.
+	for _, s := range []string{"1563355800", "1563355800123", "1563355800123456"} {
+		var at marshaler.AutoTimestamp
+		if err := at.Set(s); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(at.Format("2006-01-02T15:04:05.999999Z07:00"))
+	}
+	// Output:
+	// 2019-07-17T09:30:00Z
+	// 2019-07-17T09:30:00.123Z
+	// 2019-07-17T09:30:00.123456Z
+}
+
+func ExampleRobustDuration() {
+	for _, s := range []string{"P1DT2H", "1h30m", "2 hours 15 minutes", "90"} {
+		var d marshaler.RobustDuration
+		if err := d.Set(s); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(time.Duration(d))
+	}
+	df := marshaler.DurationFormat{Style: marshaler.DurationISO}
+	fmt.Println(df.FormatDuration(26 * time.Hour))
+	// Output:
+	// 26h0m0s
+	// 1h30m0s
+	// 2h15m0s
+	// 1m30s
+	// PT26H
+}
+
+func ExampleFiscalPeriod() {
+	p, err := marshaler.ParseFiscalPeriod("FY20-P07")
+	if err != nil {
+		log.Fatal(err)
+	}
+	// Fiscal years start in July and are named after the year they end.
+	fc := marshaler.FiscalCalendar{StartMonth: time.July}
+	fmt.Println(p, fc.PeriodRange(p))
+	// Output: FY2020 P07 2020-01-01..2020-01-31
+}
+
+func ExampleTradingCalendar() {
+	goodFriday := marshaler.Date(time.Date(2019, 4, 19, 0, 0, 0, 0, time.UTC))
+	fmt.Println(marshaler.NYSE.IsTradingDay(goodFriday))
+	fmt.Println(marshaler.NYSE.NextTradingDay(goodFriday))
+	open, close, err := marshaler.NYSE.Session(marshaler.Date(time.Date(2019, 11, 29, 0, 0, 0, 0, time.UTC)))
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(open.Format("15:04"), close.Format("15:04 MST"))
+	// Output:
+	// false
+	// 2019-04-22
+	// 09:30 13:00 EST
+}
@@ -0,0 +1,250 @@
+// Copyright 2019 Miles Barr <milesbarr2@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marshaler
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// An ExcelEpoch selects the date system of spreadsheet serial dates.
+type ExcelEpoch int
+
+const (
+	// Excel1900 is the default date system of Excel, in which serial 1 is
+	// 1 January 1900. It keeps the Lotus 1-2-3 bug of counting 29 February
+	// 1900, so serial 60, which names that nonexistent day, is rejected and
+	// serials from 61 on are one day ahead of a plain count.
+	Excel1900 ExcelEpoch = iota
+
+	// Excel1904 is the date system of early Excel for Macintosh, in which
+	// serial 0 is 1 January 1904.
+	Excel1904
+)
+
+// maxExcelSerial is the serial of 31 December 9999, the last date Excel
+// supports.
+const maxExcelSerial = 2958465
+
+// ExcelEpoch returns the date system p uses for spreadsheet serial dates.
+func (p *TimeParser) ExcelEpoch() ExcelEpoch {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.excelEpoch
+}
+
+// SetExcelEpoch sets the date system p uses for spreadsheet serial dates.
+func (p *TimeParser) SetExcelEpoch(epoch ExcelEpoch) {
+	p.mu.Lock()
+	p.excelEpoch = epoch
+	p.mu.Unlock()
+}
+
+// SetExcelSerials sets whether p parses a FlexibleTime written as a
+// spreadsheet serial date. Serials are only tried after the layouts of p and
+// after UNIX timestamps.
+func (p *TimeParser) SetExcelSerials(on bool) {
+	p.mu.Lock()
+	p.excel = on
+	p.mu.Unlock()
+}
+
+// excelSerials reports whether p parses a FlexibleTime written as a
+// spreadsheet serial date.
+func (p *TimeParser) excelSerials() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.excel
+}
+
+// ParseExcelSerial parses s, a spreadsheet serial date such as "43650.5", in
+// the date system and location of p. The fractional part is the time of day,
+// rounded to the nearest millisecond.
+func (p *TimeParser) ParseExcelSerial(s string) (time.Time, error) {
+	return parseExcelSerial(strings.TrimSpace(s), p.ExcelEpoch(), p.Location())
+}
+
+// parseExcelSerial parses the serial date s in the given date system and
+// location.
+func parseExcelSerial(s string, epoch ExcelEpoch, loc *time.Location) (time.Time, error) {
+	neg, digits, point, ok := splitDecimal(s)
+	if !ok || neg {
+		return time.Time{}, numError("ParseExcelSerial", s, strconv.ErrSyntax)
+	}
+	// Convert the serial to a whole number of milliseconds.
+	u, _ := new(big.Int).SetString(digits, 10)
+	serial, _ := newDecimal(u, int32(len(digits)-point)).Mul(NewDecimal(86400000, 0)).Round(0, RoundHalfAway)
+	ms := serial.int()
+	days, msOfDay := ms.DivMod(ms, big.NewInt(86400000), new(big.Int))
+	if !days.IsInt64() || days.Int64() > maxExcelSerial {
+		return time.Time{}, numError("ParseExcelSerial", s, strconv.ErrRange)
+	}
+	d := int(days.Int64())
+	if epoch == Excel1900 && d == 60 {
+		return time.Time{}, numError("ParseExcelSerial", s, strconv.ErrRange)
+	}
+	// Build the time from wall-clock fields, so that the time of day is
+	// unaffected by daylight saving time.
+	y, m, day := 1899, time.December, 31
+	switch {
+	case epoch == Excel1904:
+		y, m, day = 1904, time.January, 1
+	case d >= 61:
+		day = 30
+	}
+	return time.Date(y, m, day+d, 0, 0, 0, int(msOfDay.Int64())*int(time.Millisecond), loc), nil
+}
+
+// formatExcelSerial formats t as a spreadsheet serial date in the given date
+// system. The time of day is included only if withTime is set.
+func formatExcelSerial(t time.Time, epoch ExcelEpoch, withTime bool) string {
+	// Count days between wall-clock dates, which is unaffected by daylight
+	// saving time.
+	y, m, d := t.Date()
+	date := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	var days int64
+	switch {
+	case epoch == Excel1904:
+		days = civilDays(date, time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC))
+	case date.Before(time.Date(1900, 3, 1, 0, 0, 0, 0, time.UTC)):
+		days = civilDays(date, time.Date(1899, 12, 31, 0, 0, 0, 0, time.UTC))
+	default:
+		days = civilDays(date, time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC))
+	}
+	if !withTime {
+		return strconv.FormatInt(days, 10)
+	}
+	h, min, sec := t.Clock()
+	ms := int64(h)*3600000 + int64(min)*60000 + int64(sec)*1000 + int64(t.Nanosecond())/1000000
+	f := float64(days) + float64(ms)/86400000
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// civilDays returns the number of days from the UTC midnight from to the UTC
+// midnight to.
+func civilDays(to, from time.Time) int64 {
+	return (to.Unix() - from.Unix()) / 86400
+}
+
+// An ExcelDate is a time.Time that is marshaled and unmarshaled as a
+// spreadsheet serial date, such as 43650, in the date system and location of
+// DefaultTimeParser. Any fractional part is ignored.
+type ExcelDate time.Time
+
+// String implements the flag.Value interface.
+func (ed ExcelDate) String() string {
+	return time.Time(ed).Format(dateLayout)
+}
+
+// Set implements the flag.Value interface.
+func (ed *ExcelDate) Set(s string) error {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	t, err := DefaultTimeParser.ParseExcelSerial(s)
+	if err != nil {
+		return fmt.Errorf("marshaler.ExcelDate.Set: cannot parse \"%s\"", s)
+	}
+	y, m, d := t.Date()
+	*ed = ExcelDate(time.Date(y, m, d, 0, 0, 0, 0, t.Location()))
+	return nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (ed ExcelDate) MarshalText() ([]byte, error) {
+	return []byte(formatExcelSerial(time.Time(ed), DefaultTimeParser.ExcelEpoch(), false)), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (ed *ExcelDate) UnmarshalText(text []byte) error {
+	return ed.Set(string(text))
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (ed ExcelDate) MarshalJSON() ([]byte, error) {
+	return ed.MarshalText()
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (ed *ExcelDate) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		b = []byte(s)
+	}
+	return ed.UnmarshalText(b)
+}
+
+// Format wraps time.Time.Format.
+func (ed ExcelDate) Format(layout string) string {
+	return time.Time(ed).Format(layout)
+}
+
+// An ExcelDateTime is a time.Time that is marshaled and unmarshaled as a
+// spreadsheet serial date with a fractional time of day, such as 43650.5, in
+// the date system and location of DefaultTimeParser.
+type ExcelDateTime time.Time
+
+// String implements the flag.Value interface.
+func (edt ExcelDateTime) String() string {
+	return time.Time(edt).Format(dateTimeLayout)
+}
+
+// Set implements the flag.Value interface.
+func (edt *ExcelDateTime) Set(s string) error {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	t, err := DefaultTimeParser.ParseExcelSerial(s)
+	if err != nil {
+		return fmt.Errorf("marshaler.ExcelDateTime.Set: cannot parse \"%s\"", s)
+	}
+	*edt = ExcelDateTime(t)
+	return nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (edt ExcelDateTime) MarshalText() ([]byte, error) {
+	return []byte(formatExcelSerial(time.Time(edt), DefaultTimeParser.ExcelEpoch(), true)), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (edt *ExcelDateTime) UnmarshalText(text []byte) error {
+	return edt.Set(string(text))
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (edt ExcelDateTime) MarshalJSON() ([]byte, error) {
+	return edt.MarshalText()
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (edt *ExcelDateTime) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		b = []byte(s)
+	}
+	return edt.UnmarshalText(b)
+}
+
+// Format wraps time.Time.Format.
+func (edt ExcelDateTime) Format(layout string) string {
+	return time.Time(edt).Format(layout)
+}
@@ -0,0 +1,84 @@
+// Copyright 2019 Miles Barr <milesbarr2@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marshaler_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jadefox10200/marshaler"
+)
+
+func TestParseExcelSerial(t *testing.T) {
+	ny := mustLoadLocation(t, "America/New_York")
+	tests := []struct {
+		epoch marshaler.ExcelEpoch
+		loc   *time.Location
+		in    string
+		want  string
+	}{
+		{marshaler.Excel1900, time.UTC, "1", "1900-01-01T00:00:00Z"},
+		{marshaler.Excel1900, time.UTC, "59", "1900-02-28T00:00:00Z"},
+		{marshaler.Excel1904, time.UTC, "60", "1904-03-01T00:00:00Z"},
+		{marshaler.Excel1900, time.UTC, "61", "1900-03-01T00:00:00Z"},
+		{marshaler.Excel1900, time.UTC, "43650.5", "2019-07-04T12:00:00Z"},
+		{marshaler.Excel1900, time.UTC, "43650.25", "2019-07-04T06:00:00Z"},
+		{marshaler.Excel1900, time.UTC, "2958465", "9999-12-31T00:00:00Z"},
+		{marshaler.Excel1904, time.UTC, "0", "1904-01-01T00:00:00Z"},
+		{marshaler.Excel1904, time.UTC, "42188.5", "2019-07-04T12:00:00Z"},
+		// Times of day are wall-clock times on days when clocks change.
+		{marshaler.Excel1900, ny, "44269.5", "2021-03-14T12:00:00-04:00"},
+		{marshaler.Excel1900, ny, "44269.125", "2021-03-14T03:00:00-04:00"},
+		{marshaler.Excel1900, ny, "44507.75", "2021-11-07T18:00:00-05:00"},
+		{marshaler.Excel1904, ny, "42807.5", "2021-03-14T12:00:00-04:00"},
+	}
+	for _, tt := range tests {
+		p := &marshaler.TimeParser{}
+		p.SetExcelEpoch(tt.epoch)
+		p.SetLocation(tt.loc)
+		got, err := p.ParseExcelSerial(tt.in)
+		if err != nil {
+			t.Errorf("ParseExcelSerial(%q): %v", tt.in, err)
+			continue
+		}
+		if got.Format(time.RFC3339) != tt.want {
+			t.Errorf("ParseExcelSerial(%q) with epoch %d in %s = %s, want %s", tt.in, tt.epoch, tt.loc, got.Format(time.RFC3339), tt.want)
+		}
+	}
+	p := &marshaler.TimeParser{}
+	// Serial 60 is the nonexistent 29 February 1900.
+	for _, in := range []string{"", "-1", "abc", "2958466", "60", "60.5"} {
+		if _, err := p.ParseExcelSerial(in); err == nil {
+			t.Errorf("ParseExcelSerial(%q): want error", in)
+		}
+	}
+}
+
+func TestExcelDateRoundTrip(t *testing.T) {
+	for _, in := range []string{"1", "59", "61", "43650"} {
+		var ed marshaler.ExcelDate
+		if err := ed.Set(in); err != nil {
+			t.Errorf("Set(%q): %v", in, err)
+			continue
+		}
+		if b, err := ed.MarshalText(); err != nil || string(b) != in {
+			t.Errorf("Set(%q) then MarshalText = %s, %v", in, b, err)
+		}
+	}
+	var ed marshaler.ExcelDate
+	if err := ed.Set("60"); err == nil {
+		t.Errorf("Set(60) = %s, want error", ed)
+	}
+}
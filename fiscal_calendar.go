@@ -0,0 +1,208 @@
+// Copyright 2019 Miles Barr <milesbarr2@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marshaler
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A FiscalCalendar describes how fiscal years are divided into quarters and
+// periods. The zero value describes fiscal years that match calendar years,
+// with calendar months as periods.
+type FiscalCalendar struct {
+	// StartMonth is the month in which fiscal years start. If zero, fiscal
+	// years start in January.
+	StartMonth time.Month
+
+	// NamedByStart names a fiscal year after the calendar year in which it
+	// starts. Otherwise it is named after the calendar year in which it
+	// ends, so with a July start FY2020 runs from July 2019 to June 2020.
+	NamedByStart bool
+
+	// Weeks is the number of weeks in each of the three periods of a
+	// quarter of a week-based calendar, such as {4, 4, 5}. If zero, periods
+	// are calendar months. In a week-based calendar, fiscal years end on the
+	// last EndWeekday of the month before StartMonth, or the EndWeekday
+	// nearest the end of that month if Nearest is set, and the extra week of
+	// a 53-week year is added to the last period.
+	Weeks      [3]int
+	EndWeekday time.Weekday
+	Nearest    bool
+}
+
+// DefaultFiscalCalendar is the FiscalCalendar used by the fiscal types. It
+// should be configured before any values are unmarshaled.
+var DefaultFiscalCalendar FiscalCalendar
+
+// startMonth returns the month in which fiscal years start.
+func (fc FiscalCalendar) startMonth() time.Month {
+	if fc.StartMonth == 0 {
+		return time.January
+	}
+	return fc.StartMonth
+}
+
+// weekBased reports whether fc has week-based periods.
+func (fc FiscalCalendar) weekBased() bool {
+	return fc.Weeks != [3]int{}
+}
+
+// startYear returns the calendar year in which fiscal year fy starts, or
+// nominally starts in a week-based calendar.
+func (fc FiscalCalendar) startYear(fy int) int {
+	if fc.startMonth() != time.January && !fc.NamedByStart {
+		return fy - 1
+	}
+	return fy
+}
+
+// yearStart returns the first day of fiscal year fy.
+func (fc FiscalCalendar) yearStart(fy int) Date {
+	start := newDate(fc.startYear(fy), fc.startMonth(), 1)
+	if !fc.weekBased() {
+		return start
+	}
+	// The previous fiscal year ends on EndWeekday near the end of the month
+	// before start.
+	end := start.AddDays(-1)
+	back := (int(time.Time(end).Weekday()) - int(fc.EndWeekday) + 7) % 7
+	if fc.Nearest && back > 3 {
+		back -= 7
+	}
+	return end.AddDays(1 - back)
+}
+
+// periodStart returns the first day of period p of fiscal year fy, where
+// period 13 is the first period of the next year.
+func (fc FiscalCalendar) periodStart(fy, p int) Date {
+	if p > 12 {
+		return fc.yearStart(fy + 1)
+	}
+	if !fc.weekBased() {
+		return newDate(fc.startYear(fy), fc.startMonth()+time.Month(p-1), 1)
+	}
+	weeks := 0
+	for i := 1; i < p; i++ {
+		weeks += fc.Weeks[(i-1)%3]
+	}
+	return fc.yearStart(fy).AddDays(7 * weeks)
+}
+
+// periodRange returns the days from the start of period first through the
+// end of period last of fiscal year fy.
+func (fc FiscalCalendar) periodRange(fy, first, last int) DateRange {
+	return DateRange{
+		Start:        fc.periodStart(fy, first),
+		End:          fc.periodStart(fy, last+1).AddDays(-1),
+		InclusiveEnd: true,
+	}
+}
+
+// YearRange returns the days of fiscal year y.
+func (fc FiscalCalendar) YearRange(y FiscalYear) DateRange {
+	return fc.periodRange(int(y), 1, 12)
+}
+
+// QuarterRange returns the days of fiscal quarter q.
+func (fc FiscalCalendar) QuarterRange(q FiscalQuarter) DateRange {
+	return fc.periodRange(q.Year, 3*q.Quarter-2, 3*q.Quarter)
+}
+
+// PeriodRange returns the days of fiscal period p.
+func (fc FiscalCalendar) PeriodRange(p FiscalPeriod) DateRange {
+	return fc.periodRange(p.Year, p.Period, p.Period)
+}
+
+// PeriodOf returns the fiscal period that contains d.
+func (fc FiscalCalendar) PeriodOf(d Date) FiscalPeriod {
+	t := time.Time(d)
+	fy := t.Year()
+	if fc.startMonth() != time.January && !fc.NamedByStart && t.Month() >= fc.startMonth() {
+		fy++
+	}
+	// Week-based years start up to a week away from the start of the month.
+	for d.Before(fc.yearStart(fy)) {
+		fy--
+	}
+	for !d.Before(fc.yearStart(fy + 1)) {
+		fy++
+	}
+	p := 12
+	for d.Before(fc.periodStart(fy, p)) {
+		p--
+	}
+	return FiscalPeriod{fy, p}
+}
+
+// QuarterOf returns the fiscal quarter that contains d.
+func (fc FiscalCalendar) QuarterOf(d Date) FiscalQuarter {
+	p := fc.PeriodOf(d)
+	return FiscalQuarter{p.Year, (p.Period-1)/3 + 1}
+}
+
+// YearOf returns the fiscal year that contains d.
+func (fc FiscalCalendar) YearOf(d Date) FiscalYear {
+	return FiscalYear(fc.PeriodOf(d).Year)
+}
+
+// parseFiscal parses a fiscal label such as "FY2020", "FY20 Q2", "Q2 FY2020"
+// or "FY20-P07". It returns the year and, if the label names a quarter or
+// period, 'Q' or 'P' and its number. Two-digit years are in the 2000s.
+func parseFiscal(s string) (year int, kind byte, n int, ok bool) {
+	s = strings.Map(func(r rune) rune {
+		if r == ' ' || r == '-' || r == '/' || r == '_' {
+			return -1
+		}
+		return r
+	}, strings.ToUpper(s))
+	// Move a leading quarter or period after the year.
+	if s != "" && (s[0] == 'Q' || s[0] == 'P') {
+		if i := strings.Index(s, "FY"); i > 0 {
+			s = s[i:] + s[:i]
+		}
+	}
+	if !strings.HasPrefix(s, "FY") {
+		return 0, 0, 0, false
+	}
+	s = s[2:]
+	i := 0
+	for i < len(s) && isDigitByte(s[i]) {
+		i++
+	}
+	switch i {
+	case 2:
+		year, _ = strconv.Atoi(s[:i])
+		year += 2000
+	case 4:
+		year, _ = strconv.Atoi(s[:i])
+	default:
+		return 0, 0, 0, false
+	}
+	s = s[i:]
+	if s == "" {
+		return year, 0, 0, true
+	}
+	kind, s = s[0], s[1:]
+	if kind != 'Q' && kind != 'P' || s == "" || len(s) > 2 || !isDigits(s) {
+		return 0, 0, 0, false
+	}
+	n, _ = strconv.Atoi(s)
+	if kind == 'Q' && (n < 1 || n > 4) || kind == 'P' && (n < 1 || n > 12) {
+		return 0, 0, 0, false
+	}
+	return year, kind, n, true
+}
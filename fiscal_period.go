@@ -0,0 +1,78 @@
+// Copyright 2019 Miles Barr <milesbarr2@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marshaler
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A FiscalPeriod is one of the twelve periods of a fiscal year of
+// DefaultFiscalCalendar, which are calendar months unless the calendar is
+// week-based. It can be unmarshaled from "FY2020 P07", "FY20-P07", "FY20P7"
+// or "P07 FY2020" and is marshaled in "FYYYYY Pnn" format.
+type FiscalPeriod struct {
+	Year   int
+	Period int
+}
+
+// FiscalPeriodOf returns the fiscal period of DefaultFiscalCalendar that
+// contains d.
+func FiscalPeriodOf(d Date) FiscalPeriod {
+	return DefaultFiscalCalendar.PeriodOf(d)
+}
+
+// ParseFiscalPeriod parses s as a FiscalPeriod.
+func ParseFiscalPeriod(s string) (FiscalPeriod, error) {
+	year, kind, n, ok := parseFiscal(s)
+	if !ok || kind != 'P' {
+		return FiscalPeriod{}, fmt.Errorf("marshaler.ParseFiscalPeriod: cannot parse \"%s\"", s)
+	}
+	return FiscalPeriod{year, n}, nil
+}
+
+// Range returns the days of p in DefaultFiscalCalendar.
+func (p FiscalPeriod) Range() DateRange {
+	return DefaultFiscalCalendar.PeriodRange(p)
+}
+
+// String implements the flag.Value interface.
+func (p FiscalPeriod) String() string {
+	return fmt.Sprintf("FY%04d P%02d", p.Year, p.Period)
+}
+
+// Set implements the flag.Value interface.
+func (p *FiscalPeriod) Set(s string) error {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	v, err := ParseFiscalPeriod(s)
+	if err != nil {
+		return fmt.Errorf("marshaler.FiscalPeriod.Set: cannot parse \"%s\"", s)
+	}
+	*p = v
+	return nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (p FiscalPeriod) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (p *FiscalPeriod) UnmarshalText(text []byte) error {
+	return p.Set(string(text))
+}
@@ -0,0 +1,77 @@
+// Copyright 2019 Miles Barr <milesbarr2@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marshaler
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A FiscalQuarter is a quarter of a fiscal year of DefaultFiscalCalendar. It
+// can be unmarshaled from "FY2020 Q2", "FY20Q2", "FY2020-Q2" or "Q2 FY2020"
+// and is marshaled in "FYYYYY Qn" format.
+type FiscalQuarter struct {
+	Year    int
+	Quarter int
+}
+
+// FiscalQuarterOf returns the fiscal quarter of DefaultFiscalCalendar that
+// contains d.
+func FiscalQuarterOf(d Date) FiscalQuarter {
+	return DefaultFiscalCalendar.QuarterOf(d)
+}
+
+// ParseFiscalQuarter parses s as a FiscalQuarter.
+func ParseFiscalQuarter(s string) (FiscalQuarter, error) {
+	year, kind, n, ok := parseFiscal(s)
+	if !ok || kind != 'Q' {
+		return FiscalQuarter{}, fmt.Errorf("marshaler.ParseFiscalQuarter: cannot parse \"%s\"", s)
+	}
+	return FiscalQuarter{year, n}, nil
+}
+
+// Range returns the days of q in DefaultFiscalCalendar.
+func (q FiscalQuarter) Range() DateRange {
+	return DefaultFiscalCalendar.QuarterRange(q)
+}
+
+// String implements the flag.Value interface.
+func (q FiscalQuarter) String() string {
+	return fmt.Sprintf("FY%04d Q%d", q.Year, q.Quarter)
+}
+
+// Set implements the flag.Value interface.
+func (q *FiscalQuarter) Set(s string) error {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	v, err := ParseFiscalQuarter(s)
+	if err != nil {
+		return fmt.Errorf("marshaler.FiscalQuarter.Set: cannot parse \"%s\"", s)
+	}
+	*q = v
+	return nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (q FiscalQuarter) MarshalText() ([]byte, error) {
+	return []byte(q.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (q *FiscalQuarter) UnmarshalText(text []byte) error {
+	return q.Set(string(text))
+}
@@ -0,0 +1,74 @@
+// Copyright 2019 Miles Barr <milesbarr2@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marshaler
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A FiscalYear is a fiscal year of DefaultFiscalCalendar. It can be
+// unmarshaled from "FY2020", "FY20" or "fy 2020" and is marshaled in FYYYYY
+// format.
+type FiscalYear int
+
+// FiscalYearOf returns the fiscal year of DefaultFiscalCalendar that
+// contains d.
+func FiscalYearOf(d Date) FiscalYear {
+	return DefaultFiscalCalendar.YearOf(d)
+}
+
+// ParseFiscalYear parses s as a FiscalYear.
+func ParseFiscalYear(s string) (FiscalYear, error) {
+	year, kind, _, ok := parseFiscal(s)
+	if !ok || kind != 0 {
+		return 0, fmt.Errorf("marshaler.ParseFiscalYear: cannot parse \"%s\"", s)
+	}
+	return FiscalYear(year), nil
+}
+
+// Range returns the days of y in DefaultFiscalCalendar.
+func (y FiscalYear) Range() DateRange {
+	return DefaultFiscalCalendar.YearRange(y)
+}
+
+// String implements the flag.Value interface.
+func (y FiscalYear) String() string {
+	return fmt.Sprintf("FY%04d", int(y))
+}
+
+// Set implements the flag.Value interface.
+func (y *FiscalYear) Set(s string) error {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	v, err := ParseFiscalYear(s)
+	if err != nil {
+		return fmt.Errorf("marshaler.FiscalYear.Set: cannot parse \"%s\"", s)
+	}
+	*y = v
+	return nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (y FiscalYear) MarshalText() ([]byte, error) {
+	return []byte(y.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (y *FiscalYear) UnmarshalText(text []byte) error {
+	return y.Set(string(text))
+}
@@ -15,6 +15,7 @@
 package marshaler
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
@@ -24,12 +25,45 @@ import (
 // unmarshaling.
 type FlexibleTime time.Time
 
-// flexibleTimeLayouts are the layouts used for parsing a FlexibleTime.
+// flexibleTimeLayouts are the default layouts used for parsing a
+// FlexibleTime. They can be changed through a TimeParser.
 var flexibleTimeLayouts = [...]string{
-	"2006-01-02",
+	dateLayout,
 	"2006-01-02 15:04",
-	"2006-01-02 15:04:05",
+	dateTimeLayout,
 	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02T15:04:05Z0700",
+	"20060102T150405",
+	"20060102T150405Z0700",
+	"20060102",
+	"2006/01/02",
+	"2006/01/02 15:04",
+	"2006/01/02 15:04:05",
+	time.RFC1123,
+	time.RFC1123Z,
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	time.RFC822,
+	time.RFC822Z,
+	time.RFC850,
+	time.ANSIC,
+	time.UnixDate,
+	time.RubyDate,
+	"Jan 2, 2006",
+	"Jan 2, 2006 15:04",
+	"Jan 2, 2006 15:04:05",
+	"Jan 2, 2006 3:04 PM",
+	"Jan 2, 2006 3:04:05 PM",
+	"January 2, 2006",
+	"Jan 2 2006",
+	"2 Jan 2006",
+	"2 January 2006",
+	"02-Jan-2006",
+	"02-Jan-2006 15:04",
+	"02-Jan-2006 15:04:05",
+	"02-Jan-06",
 }
 
 // Strings implements the flag.Value interface.
@@ -37,20 +71,27 @@ func (ft FlexibleTime) String() string {
 	return time.Time(ft).String()
 }
 
-// Set implements the flag.Value interface.
+// Set implements the flag.Value interface. The layouts of DefaultTimeParser
+// are tried in order, followed by numeric dates such as "03/04/2019", which
+// are read in the order of DefaultTimeParser. Strings without an explicit
+// time zone are interpreted in its location.
 func (ft *FlexibleTime) Set(s string) error {
+	return ft.SetInLocation(s, DefaultTimeParser.Location())
+}
+
+// SetInLocation is like Set but interprets strings without an explicit time
+// zone in loc.
+func (ft *FlexibleTime) SetInLocation(s string, loc *time.Location) error {
 	s = strings.TrimSpace(s)
 	if s == "" {
 		return nil
 	}
-	for _, format := range flexibleTimeLayouts {
-		t, err := time.Parse(format, s)
-		if err == nil {
-			*ft = FlexibleTime(t)
-			return nil
-		}
+	t, err := DefaultTimeParser.parseFlexible(s, loc)
+	if err != nil {
+		return fmt.Errorf("marshaler.FlexibleTime.Set: cannot parse \"%s\"", s)
 	}
-	return fmt.Errorf("marshaler.FlexibleTime.Set: cannot parse \"%s\"", s)
+	*ft = FlexibleTime(t)
+	return nil
 }
 
 // MarshalText implements the encoding.TextMarshaler interface.
@@ -63,6 +104,17 @@ func (ft *FlexibleTime) UnmarshalText(text []byte) error {
 	return ft.Set(string(text))
 }
 
+// UnmarshalJSON implements the json.Unmarshaler interface. A JSON number is
+// accepted if DefaultTimeParser parses UNIX timestamps; see
+// TimeParser.SetEpochNumbers.
+func (ft *FlexibleTime) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		b = []byte(s)
+	}
+	return ft.UnmarshalText(b)
+}
+
 // Format wraps time.Time.Format.
 func (ft FlexibleTime) Format(layout string) string {
 	return time.Time(ft).Format(layout)
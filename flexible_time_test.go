@@ -0,0 +1,85 @@
+// Copyright 2019 Miles Barr <milesbarr2@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marshaler_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jadefox10200/marshaler"
+)
+
+func TestParseFlexibleTimeZoneAbbreviation(t *testing.T) {
+	ny := mustLoadLocation(t, "America/New_York")
+	kolkata := mustLoadLocation(t, "Asia/Kolkata")
+	shanghai := mustLoadLocation(t, "Asia/Shanghai")
+	tests := []struct {
+		loc      *time.Location
+		in, want string
+	}{
+		{time.UTC, "Wed, 17 Jul 2019 09:30:00 UTC", "2019-07-17T09:30:00Z"},
+		{time.UTC, "Wed, 17 Jul 2019 09:30:00 GMT", "2019-07-17T09:30:00Z"},
+		// Abbreviations that name a time zone.
+		{time.UTC, "Thu, 17 Jan 2019 09:30:00 EST", "2019-01-17T09:30:00-05:00"},
+		{time.UTC, "Thu, 17 Jan 2019 09:30:00 CET", "2019-01-17T09:30:00+01:00"},
+		{time.UTC, "Thu, 17 Jan 2019 09:30:00 MST", "2019-01-17T09:30:00-07:00"},
+		{time.UTC, "17 Jan 19 09:30 EST", "2019-01-17T09:30:00-05:00"},
+		{time.UTC, "Thu Jan 17 09:30:00 EST 2019", "2019-01-17T09:30:00-05:00"},
+		// Common US and European abbreviations.
+		{time.UTC, "Wed, 17 Jul 2019 09:30:00 EDT", "2019-07-17T09:30:00-04:00"},
+		{time.UTC, "Wed, 17 Jul 2019 09:30:00 PDT", "2019-07-17T09:30:00-07:00"},
+		{time.UTC, "Wed, 17 Jul 2019 09:30:00 CEST", "2019-07-17T09:30:00+02:00"},
+		{time.UTC, "Wed, 17 Jul 2019 09:30:00 BST", "2019-07-17T09:30:00+01:00"},
+		{time.UTC, "Thu, 17 Jan 2019 09:30:00 CST", "2019-01-17T09:30:00-06:00"},
+		{time.UTC, "Wednesday, 17-Jul-19 09:30:00 EEST", "2019-07-17T09:30:00+03:00"},
+		{time.UTC, "Wednesday, 17-Jul-19 09:30:00 AKDT", "2019-07-17T09:30:00-08:00"},
+		{ny, "Thu, 17 Jan 2019 09:30:00 CST", "2019-01-17T09:30:00-06:00"},
+		{kolkata, "Wed, 17 Jul 2019 09:30:00 IST", "2019-07-17T09:30:00+05:30"},
+		{shanghai, "Thu, 17 Jan 2019 09:30:00 CST", "2019-01-17T09:30:00+08:00"},
+		// A comma before fractional seconds.
+		{time.UTC, "2019-07-17 09:30:00,25", "2019-07-17T09:30:00.25Z"},
+		// Abbreviations known in the location of the parser.
+		{ny, "Wed, 17 Jul 2019 09:30:00 EDT", "2019-07-17T09:30:00-04:00"},
+		{ny, "Thu, 17 Jan 2019 09:30:00 EST", "2019-01-17T09:30:00-05:00"},
+	}
+	for _, tt := range tests {
+		p := &marshaler.TimeParser{}
+		p.SetLocation(tt.loc)
+		ft, err := p.ParseFlexibleTime(tt.in)
+		if err != nil {
+			t.Errorf("ParseFlexibleTime(%q) in %s: %v", tt.in, tt.loc, err)
+			continue
+		}
+		if got := time.Time(ft).Format(time.RFC3339Nano); got != tt.want {
+			t.Errorf("ParseFlexibleTime(%q) in %s = %s, want %s", tt.in, tt.loc, got, tt.want)
+		}
+	}
+	// Ambiguous and unknown abbreviations are not read as UTC.
+	london := mustLoadLocation(t, "Europe/London")
+	for _, tt := range []struct {
+		loc *time.Location
+		in  string
+	}{
+		{time.UTC, "Wed, 17 Jul 2019 09:30:00 IST"},
+		{time.UTC, "Wed, 17 Jul 2019 09:30:00 XYZ"},
+		{london, "Thu, 17 Jan 2019 09:30:00 CST"},
+	} {
+		p := &marshaler.TimeParser{}
+		p.SetLocation(tt.loc)
+		if ft, err := p.ParseFlexibleTime(tt.in); err == nil {
+			t.Errorf("ParseFlexibleTime(%q) in %s = %s, want error", tt.in, tt.loc, ft)
+		}
+	}
+}
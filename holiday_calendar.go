@@ -0,0 +1,338 @@
+// Copyright 2019 Miles Barr <milesbarr2@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marshaler
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// A HolidayCalendar reports which days are holidays. Saturdays and Sundays
+// are never business days, so a HolidayCalendar need not report them.
+type HolidayCalendar interface {
+	IsHoliday(d Date) bool
+}
+
+// IsWeekend reports whether d is a Saturday or a Sunday.
+func (d Date) IsWeekend() bool {
+	wd := time.Time(d).Weekday()
+	return wd == time.Saturday || wd == time.Sunday
+}
+
+// IsBusinessDay reports whether d is a weekday that is not a holiday in cal.
+// A nil cal has no holidays.
+func (d Date) IsBusinessDay(cal HolidayCalendar) bool {
+	return !d.IsWeekend() && (cal == nil || !cal.IsHoliday(d))
+}
+
+// maxBusinessDayGap is the number of consecutive days without a business
+// day after which AddBusinessDays gives up, so that a calendar in which
+// every weekday is a holiday cannot make it loop forever.
+const maxBusinessDayGap = 3 * 366
+
+// AddBusinessDays returns the date n business days after d, or before d if n
+// is negative. If n is zero, d is returned even if it is not a business day.
+// The zero Date is returned if cal has no business day for maxBusinessDayGap
+// days in a row.
+func (d Date) AddBusinessDays(n int, cal HolidayCalendar) Date {
+	step := 1
+	if n < 0 {
+		step, n = -1, -n
+	}
+	for ; n > 0; n-- {
+		d = d.AddDays(step)
+		for gap := 1; !d.IsBusinessDay(cal); gap++ {
+			if gap >= maxBusinessDayGap {
+				return Date{}
+			}
+			d = d.AddDays(step)
+		}
+	}
+	return d
+}
+
+// NextBusinessDay returns the first business day after d, or the zero Date
+// if there is none, as for AddBusinessDays.
+func (d Date) NextBusinessDay(cal HolidayCalendar) Date {
+	return d.AddBusinessDays(1, cal)
+}
+
+// PrevBusinessDay returns the last business day before d, or the zero Date
+// if there is none, as for AddBusinessDays.
+func (d Date) PrevBusinessDay(cal HolidayCalendar) Date {
+	return d.AddBusinessDays(-1, cal)
+}
+
+// BusinessDaysBetween returns the number of business days after d up to and
+// including e, which is negative if e is before d. It checks each day in
+// turn, so it takes time proportional to the number of days from d to e.
+func (d Date) BusinessDaysBetween(e Date, cal HolidayCalendar) int {
+	if e.Before(d) {
+		return -e.BusinessDaysBetween(d, cal)
+	}
+	n := 0
+	for d = d.AddDays(1); !d.After(e); d = d.AddDays(1) {
+		if d.IsBusinessDay(cal) {
+			n++
+		}
+	}
+	return n
+}
+
+// An Observance is a rule for moving a holiday that falls on a weekend to a
+// weekday.
+type Observance int
+
+const (
+	// ObserveActual does not move holidays.
+	ObserveActual Observance = iota
+
+	// ObserveNearestWeekday moves a Saturday holiday to the Friday before
+	// and a Sunday holiday to the Monday after.
+	ObserveNearestWeekday
+
+	// ObserveNextWeekday moves a weekend holiday to the next weekday that
+	// is not already a holiday, as with substitute days in the UK.
+	ObserveNextWeekday
+
+	// ObserveSundayToMonday moves a Sunday holiday to the Monday after and
+	// does not move a Saturday holiday.
+	ObserveSundayToMonday
+)
+
+// A HolidayRule describes a holiday that recurs every year. The holiday is
+// EasterOffset days after Western Easter Sunday if Easter is set, the Nth
+// Weekday of Month if N is not zero, and Month Day otherwise, moved by Offset
+// days.
+type HolidayRule struct {
+	Name string
+
+	Month time.Month
+	Day   int
+
+	// N counts from the end of Month if it is negative, so -1 is the last
+	// Weekday of Month.
+	N       int
+	Weekday time.Weekday
+
+	Easter       bool
+	EasterOffset int
+
+	Offset int
+
+	Observance Observance
+
+	// FromYear and ToYear are the first and last years in which the
+	// holiday is observed. Zero means no limit.
+	FromYear int
+	ToYear   int
+}
+
+// FixedHoliday returns a HolidayRule for a holiday on the same day every
+// year, such as December 25.
+func FixedHoliday(name string, month time.Month, day int, o Observance) HolidayRule {
+	return HolidayRule{Name: name, Month: month, Day: day, Observance: o}
+}
+
+// NthWeekdayHoliday returns a HolidayRule for a holiday on the nth weekday
+// of a month, such as the fourth Thursday of November. If n is negative it
+// counts from the end of the month.
+func NthWeekdayHoliday(name string, month time.Month, weekday time.Weekday, n int) HolidayRule {
+	return HolidayRule{Name: name, Month: month, N: n, Weekday: weekday}
+}
+
+// EasterHoliday returns a HolidayRule for a holiday offset days after Western
+// Easter Sunday, such as Good Friday at -2.
+func EasterHoliday(name string, offset int) HolidayRule {
+	return HolidayRule{Name: name, Easter: true, EasterOffset: offset}
+}
+
+// Date returns the date of the holiday in year, before any observance is
+// applied. It reports false if the holiday does not occur in year.
+func (r HolidayRule) Date(year int) (Date, bool) {
+	if r.FromYear != 0 && year < r.FromYear || r.ToYear != 0 && year > r.ToYear {
+		return Date{}, false
+	}
+	var (
+		d  Date
+		ok bool
+	)
+	switch {
+	case r.Easter:
+		d, ok = easter(year).AddDays(r.EasterOffset), true
+	case r.N > 0:
+		first := newDate(year, r.Month, 1)
+		offset := (int(r.Weekday) - int(time.Time(first).Weekday()) + 7) % 7
+		d = first.AddDays(offset + 7*(r.N-1))
+		ok = time.Time(d).Month() == r.Month
+	case r.N < 0:
+		last := newDate(year, r.Month+1, 0)
+		offset := (int(time.Time(last).Weekday()) - int(r.Weekday) + 7) % 7
+		d = last.AddDays(-offset + 7*(r.N+1))
+		ok = time.Time(d).Month() == r.Month
+	default:
+		d = newDate(year, r.Month, r.Day)
+		ok = time.Time(d).Day() == r.Day
+	}
+	return d.AddDays(r.Offset), ok
+}
+
+// easter returns Western Easter Sunday of year, computed with the anonymous
+// Gregorian algorithm.
+func easter(year int) Date {
+	a := year % 19
+	b, c := year/100, year%100
+	d, e := b/4, b%4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i, k := c/4, c%4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return newDate(year, time.Month(month), day)
+}
+
+// A Holiday is an occurrence of a holiday.
+type Holiday struct {
+	Name string
+
+	// Date is the day of the holiday and Observed the day on which it is
+	// observed, which differ if the holiday was moved off a weekend.
+	Date     Date
+	Observed Date
+}
+
+// A RuleCalendar is a HolidayCalendar defined by HolidayRules. The holidays
+// of each year are computed once, so Rules must not be changed after the
+// calendar is first used. A RuleCalendar is safe for concurrent use.
+type RuleCalendar struct {
+	Rules []HolidayRule
+
+	mu    sync.Mutex
+	years map[int]*holidayYear
+}
+
+// A holidayYear holds the holidays of a year and indexes them by the days
+// on which they fall and are observed.
+type holidayYear struct {
+	holidays []Holiday
+	days     map[time.Time]int
+}
+
+// NewRuleCalendar returns a RuleCalendar with the given rules.
+func NewRuleCalendar(rules ...HolidayRule) *RuleCalendar {
+	return &RuleCalendar{Rules: rules}
+}
+
+// Holidays returns the holidays of year ordered by their observed date. A
+// holiday near the start or end of the year may be observed in another
+// year.
+func (c *RuleCalendar) Holidays(year int) []Holiday {
+	return append([]Holiday(nil), c.year(year).holidays...)
+}
+
+// year returns the holidays of year, computing them on first use.
+func (c *RuleCalendar) year(year int) *holidayYear {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if hy, ok := c.years[year]; ok {
+		return hy
+	}
+	hs := c.holidays(year)
+	hy := &holidayYear{holidays: hs, days: make(map[time.Time]int, 2*len(hs))}
+	for i, h := range hs {
+		for _, d := range []Date{h.Observed, h.Date} {
+			if _, ok := hy.days[d.midnightUTC()]; !ok {
+				hy.days[d.midnightUTC()] = i
+			}
+		}
+	}
+	if c.years == nil {
+		c.years = make(map[int]*holidayYear)
+	}
+	c.years[year] = hy
+	return hy
+}
+
+// holidays computes the holidays of year ordered by their observed date.
+func (c *RuleCalendar) holidays(year int) []Holiday {
+	var (
+		hs          []Holiday
+		observances []Observance
+	)
+	taken := make(map[time.Time]bool)
+	for _, r := range c.Rules {
+		if d, ok := r.Date(year); ok {
+			hs = append(hs, Holiday{r.Name, d, d})
+			observances = append(observances, r.Observance)
+			if !d.IsWeekend() {
+				taken[d.midnightUTC()] = true
+			}
+		}
+	}
+	// Move weekend holidays once every weekday holiday is known, so that a
+	// substitute day never lands on another holiday.
+	for i := range hs {
+		h := &hs[i]
+		if !h.Date.IsWeekend() {
+			continue
+		}
+		wd := time.Time(h.Date).Weekday()
+		switch observances[i] {
+		case ObserveNearestWeekday:
+			if wd == time.Saturday {
+				h.Observed = h.Date.AddDays(-1)
+			} else {
+				h.Observed = h.Date.AddDays(1)
+			}
+		case ObserveNextWeekday:
+			d := h.Date.AddDays(1)
+			for d.IsWeekend() || taken[d.midnightUTC()] {
+				d = d.AddDays(1)
+			}
+			h.Observed = d
+			taken[d.midnightUTC()] = true
+		case ObserveSundayToMonday:
+			if wd == time.Sunday {
+				h.Observed = h.Date.AddDays(1)
+			}
+		}
+	}
+	sort.SliceStable(hs, func(i, j int) bool {
+		return hs[i].Observed.Before(hs[j].Observed)
+	})
+	return hs
+}
+
+// Holiday returns the holiday that falls on or is observed on d.
+func (c *RuleCalendar) Holiday(d Date) (Holiday, bool) {
+	y := time.Time(d).Year()
+	for year := y - 1; year <= y+1; year++ {
+		hy := c.year(year)
+		if i, ok := hy.days[d.midnightUTC()]; ok {
+			return hy.holidays[i], true
+		}
+	}
+	return Holiday{}, false
+}
+
+// IsHoliday implements the HolidayCalendar interface.
+func (c *RuleCalendar) IsHoliday(d Date) bool {
+	_, ok := c.Holiday(d)
+	return ok
+}
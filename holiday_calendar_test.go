@@ -0,0 +1,193 @@
+// Copyright 2019 Miles Barr <milesbarr2@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marshaler_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jadefox10200/marshaler"
+)
+
+func TestEasterHoliday(t *testing.T) {
+	want := []string{
+		"2000-04-23", "2001-04-15", "2002-03-31", "2003-04-20", "2004-04-11",
+		"2005-03-27", "2006-04-16", "2007-04-08", "2008-03-23", "2009-04-12",
+		"2010-04-04", "2011-04-24", "2012-04-08", "2013-03-31", "2014-04-20",
+		"2015-04-05", "2016-03-27", "2017-04-16", "2018-04-01", "2019-04-21",
+		"2020-04-12", "2021-04-04", "2022-04-17", "2023-04-09", "2024-03-31",
+		"2025-04-20", "2026-04-05", "2027-03-28", "2028-04-16", "2029-04-01",
+		"2030-04-21",
+	}
+	easter := marshaler.EasterHoliday("Easter Sunday", 0)
+	goodFriday := marshaler.EasterHoliday("Good Friday", -2)
+	for i, w := range want {
+		year := 2000 + i
+		d, ok := easter.Date(year)
+		if !ok || d.String() != w {
+			t.Errorf("Easter %d = %s, %v; want %s", year, d, ok, w)
+		}
+		gf, _ := goodFriday.Date(year)
+		if gf.DaysBetween(d) != 2 || time.Time(gf).Weekday() != time.Friday {
+			t.Errorf("Good Friday %d = %s", year, gf)
+		}
+	}
+}
+
+func TestRuleCalendarObservance(t *testing.T) {
+	tests := []struct {
+		o              marshaler.Observance
+		month          time.Month
+		day, year      int
+		date, observed string
+	}{
+		// July 4 2020 was a Saturday and July 4 2021 a Sunday.
+		{marshaler.ObserveActual, time.July, 4, 2020, "2020-07-04", "2020-07-04"},
+		{marshaler.ObserveNearestWeekday, time.July, 4, 2020, "2020-07-04", "2020-07-03"},
+		{marshaler.ObserveNearestWeekday, time.July, 4, 2021, "2021-07-04", "2021-07-05"},
+		{marshaler.ObserveNextWeekday, time.July, 4, 2020, "2020-07-04", "2020-07-06"},
+		{marshaler.ObserveNextWeekday, time.July, 4, 2021, "2021-07-04", "2021-07-05"},
+		{marshaler.ObserveSundayToMonday, time.July, 4, 2020, "2020-07-04", "2020-07-04"},
+		{marshaler.ObserveSundayToMonday, time.July, 4, 2021, "2021-07-04", "2021-07-05"},
+		// Weekday holidays are never moved.
+		{marshaler.ObserveNearestWeekday, time.July, 4, 2019, "2019-07-04", "2019-07-04"},
+	}
+	for _, tt := range tests {
+		cal := marshaler.NewRuleCalendar(marshaler.FixedHoliday("Holiday", tt.month, tt.day, tt.o))
+		hs := cal.Holidays(tt.year)
+		if len(hs) != 1 || hs[0].Date.String() != tt.date || hs[0].Observed.String() != tt.observed {
+			t.Errorf("observance %d in %d: holidays = %v, want %s observed %s", tt.o, tt.year, hs, tt.date, tt.observed)
+			continue
+		}
+		for _, s := range []string{tt.date, tt.observed} {
+			var d marshaler.Date
+			if err := d.Set(s); err != nil {
+				t.Fatal(err)
+			}
+			if h, ok := cal.Holiday(d); !ok || h.Name != "Holiday" {
+				t.Errorf("observance %d: Holiday(%s) = %v, %v", tt.o, d, h, ok)
+			}
+		}
+	}
+
+	// Substitute days do not land on other holidays: Christmas 2021 was a
+	// Saturday and Boxing Day a Sunday.
+	uk := marshaler.NewRuleCalendar(
+		marshaler.FixedHoliday("Christmas Day", time.December, 25, marshaler.ObserveNextWeekday),
+		marshaler.FixedHoliday("Boxing Day", time.December, 26, marshaler.ObserveNextWeekday),
+	)
+	hs := uk.Holidays(2021)
+	if len(hs) != 2 || hs[0].Observed.String() != "2021-12-27" || hs[1].Observed.String() != "2021-12-28" {
+		t.Errorf("UK Christmas 2021 = %v", hs)
+	}
+
+	// New Year's Day 2022 was a Saturday, observed on Friday 31 December
+	// 2021.
+	us := marshaler.NewRuleCalendar(marshaler.FixedHoliday("New Year's Day", time.January, 1, marshaler.ObserveNearestWeekday))
+	if h, ok := us.Holiday(date(2021, 12, 31)); !ok || h.Date.String() != "2022-01-01" {
+		t.Errorf("Holiday(2021-12-31) = %v, %v", h, ok)
+	}
+}
+
+func TestRuleCalendarMemoization(t *testing.T) {
+	cal := marshaler.NewRuleCalendar(marshaler.FixedHoliday("Christmas Day", time.December, 25, marshaler.ObserveActual))
+	hs := cal.Holidays(2019)
+	if len(hs) != 1 {
+		t.Fatalf("Holidays(2019) = %v", hs)
+	}
+	// The result is a copy.
+	hs[0].Name = "changed"
+	if got := cal.Holidays(2019)[0].Name; got != "Christmas Day" {
+		t.Errorf("Holidays(2019) after modifying a result = %q", got)
+	}
+	// The holidays of a year are computed once, so a rule added later only
+	// affects years not yet computed.
+	cal.Rules = append(cal.Rules, marshaler.FixedHoliday("Boxing Day", time.December, 26, marshaler.ObserveActual))
+	if n := len(cal.Holidays(2019)); n != 1 {
+		t.Errorf("Holidays(2019) recomputed: %d holidays", n)
+	}
+	if n := len(cal.Holidays(2025)); n != 2 {
+		t.Errorf("Holidays(2025) = %d holidays, want 2", n)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for y := 2000; y < 2050; y++ {
+				if !cal.IsHoliday(date(y, 12, 25)) {
+					t.Errorf("IsHoliday(%d-12-25) = false", y)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+// everyWeekday is a HolidayCalendar in which every day is a holiday.
+type everyWeekday struct{}
+
+func (everyWeekday) IsHoliday(marshaler.Date) bool { return true }
+
+func TestAddBusinessDays(t *testing.T) {
+	cal := marshaler.NewRuleCalendar(
+		marshaler.FixedHoliday("Independence Day", time.July, 4, marshaler.ObserveNearestWeekday),
+		marshaler.EasterHoliday("Good Friday", -2),
+	)
+	tests := []struct {
+		d    marshaler.Date
+		n    int
+		cal  marshaler.HolidayCalendar
+		want string
+	}{
+		// 2019-07-05 was a Friday.
+		{date(2019, 7, 5), 1, nil, "2019-07-08"},
+		{date(2019, 7, 5), 0, nil, "2019-07-05"},
+		{date(2019, 7, 6), 0, nil, "2019-07-06"},
+		{date(2019, 7, 6), 1, nil, "2019-07-08"},
+		{date(2019, 7, 8), -1, nil, "2019-07-05"},
+		{date(2019, 7, 5), 5, nil, "2019-07-12"},
+		{date(2019, 7, 3), 1, cal, "2019-07-05"},
+		{date(2019, 7, 5), -1, cal, "2019-07-03"},
+		// Good Friday 2019 was April 19.
+		{date(2019, 4, 18), 1, cal, "2019-04-22"},
+		{date(2019, 4, 22), -1, cal, "2019-04-18"},
+	}
+	for _, tt := range tests {
+		if got := tt.d.AddBusinessDays(tt.n, tt.cal).String(); got != tt.want {
+			t.Errorf("%s.AddBusinessDays(%d) = %s, want %s", tt.d, tt.n, got, tt.want)
+		}
+	}
+	if got := date(2019, 7, 3).BusinessDaysBetween(date(2019, 7, 10), cal); got != 4 {
+		t.Errorf("BusinessDaysBetween = %d, want 4", got)
+	}
+	if got := date(2019, 7, 10).BusinessDaysBetween(date(2019, 7, 3), nil); got != -5 {
+		t.Errorf("BusinessDaysBetween backwards = %d, want -5", got)
+	}
+
+	// A calendar without business days does not loop forever.
+	var zero marshaler.Date
+	if got := date(2019, 7, 5).AddBusinessDays(1, everyWeekday{}); got != zero {
+		t.Errorf("AddBusinessDays with no business days = %s, want the zero Date", got)
+	}
+	if got := date(2019, 7, 5).PrevBusinessDay(everyWeekday{}); got != zero {
+		t.Errorf("PrevBusinessDay with no business days = %s, want the zero Date", got)
+	}
+	if got := date(2019, 1, 1).BusinessDaysBetween(date(2020, 1, 1), everyWeekday{}); got != 0 {
+		t.Errorf("BusinessDaysBetween with no business days = %d", got)
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright 2019 Miles Barr <milesbarr2@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marshaler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// An ISOWeek is an ISO 8601 week, or a day of one. Weeks start on Monday and
+// week 1 is the week with the year's first Thursday. An ISOWeek can be
+// unmarshaled from "2019-W07", "2019W07", "2019-W07-3" or "2019W073" and is
+// marshaled in YYYY-Www or YYYY-Www-D format.
+type ISOWeek struct {
+	Year int
+	Week int
+
+	// Day is the day of the week, from 1 for Monday to 7 for Sunday, or 0
+	// for the whole week.
+	Day int
+}
+
+// ISOWeekOf returns the ISO week day of d.
+func ISOWeekOf(d Date) ISOWeek {
+	t := time.Time(d)
+	y, w := t.ISOWeek()
+	return ISOWeek{y, w, (int(t.Weekday())+6)%7 + 1}
+}
+
+// ParseISOWeek parses s as an ISOWeek.
+func ParseISOWeek(s string) (ISOWeek, error) {
+	w, ok := parseISOWeek(strings.ToUpper(strings.TrimSpace(s)))
+	if !ok {
+		return ISOWeek{}, fmt.Errorf("marshaler.ParseISOWeek: cannot parse \"%s\"", s)
+	}
+	return w, nil
+}
+
+func parseISOWeek(s string) (ISOWeek, bool) {
+	i := strings.IndexByte(s, 'W')
+	if i < 0 {
+		return ISOWeek{}, false
+	}
+	year, rest := strings.TrimRight(s[:i], "- "), s[i+1:]
+	week, day := rest, ""
+	if j := strings.IndexByte(rest, '-'); j >= 0 {
+		week, day = rest[:j], rest[j+1:]
+	} else if len(rest) == 3 {
+		week, day = rest[:2], rest[2:]
+	}
+	if len(year) != 4 || !isDigits(year) || len(week) != 2 || !isDigits(week) ||
+		!isDigits(day) || len(day) > 1 {
+		return ISOWeek{}, false
+	}
+	var w ISOWeek
+	w.Year, _ = strconv.Atoi(year)
+	w.Week, _ = strconv.Atoi(week)
+	if day != "" {
+		w.Day = int(day[0] - '0')
+		if w.Day < 1 || w.Day > 7 {
+			return ISOWeek{}, false
+		}
+	}
+	// The last week of a year is the week that contains December 28.
+	_, last := time.Date(w.Year, time.December, 28, 0, 0, 0, 0, time.UTC).ISOWeek()
+	return w, w.Week >= 1 && w.Week <= last
+}
+
+// FirstDay returns the Monday of w.
+func (w ISOWeek) FirstDay() Date {
+	// January 4 is always in week 1.
+	jan4 := time.Date(w.Year, time.January, 4, 0, 0, 0, 0, time.UTC)
+	offset := (int(jan4.Weekday()) + 6) % 7
+	return newDate(w.Year, time.January, 4-offset+7*(w.Week-1))
+}
+
+// LastDay returns the Sunday of w.
+func (w ISOWeek) LastDay() Date {
+	return Date(time.Time(w.FirstDay()).AddDate(0, 0, 6))
+}
+
+// Date returns the day of w, or its Monday if w is a whole week.
+func (w ISOWeek) Date() Date {
+	if w.Day == 0 {
+		return w.FirstDay()
+	}
+	t := time.Time(w.FirstDay())
+	return Date(t.AddDate(0, 0, w.Day-1))
+}
+
+// String implements the flag.Value interface.
+func (w ISOWeek) String() string {
+	if w.Day == 0 {
+		return fmt.Sprintf("%04d-W%02d", w.Year, w.Week)
+	}
+	return fmt.Sprintf("%04d-W%02d-%d", w.Year, w.Week, w.Day)
+}
+
+// Set implements the flag.Value interface.
+func (w *ISOWeek) Set(s string) error {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	v, err := ParseISOWeek(s)
+	if err != nil {
+		return fmt.Errorf("marshaler.ISOWeek.Set: cannot parse \"%s\"", s)
+	}
+	*w = v
+	return nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (w ISOWeek) MarshalText() ([]byte, error) {
+	return []byte(w.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (w *ISOWeek) UnmarshalText(text []byte) error {
+	return w.Set(string(text))
+}
@@ -0,0 +1,262 @@
+// Copyright 2019 Miles Barr <milesbarr2@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marshaler
+
+import (
+	"strings"
+	"unicode"
+)
+
+// A Locale describes the digit grouping character and the decimal separator
+// used when parsing numbers.
+type Locale struct {
+	// Group is the digit grouping character, or 0 if digit grouping is not
+	// permitted. A space matches any Unicode space character, including
+	// no-break spaces.
+	Group rune
+
+	// Decimal is the decimal separator. If zero, '.' is used.
+	Decimal rune
+
+	// auto reports whether the convention is detected from the string being
+	// parsed, in which case Decimal is only a preference for ambiguous input.
+	auto bool
+}
+
+var (
+	// LocaleC is the locale used by the strconv package: no digit grouping
+	// and a '.' decimal separator.
+	LocaleC = Locale{Decimal: '.'}
+
+	// LocaleEnglish groups digits with ',' and uses a '.' decimal separator,
+	// as in "1,234.56".
+	LocaleEnglish = Locale{Group: ',', Decimal: '.'}
+
+	// LocaleEuropean groups digits with '.' and uses a ',' decimal separator,
+	// as in "1.234,56".
+	LocaleEuropean = Locale{Group: '.', Decimal: ','}
+
+	// LocaleFrench groups digits with a space and uses a ',' decimal
+	// separator, as in "1 234,56".
+	LocaleFrench = Locale{Group: ' ', Decimal: ','}
+
+	// LocaleSwiss groups digits with an apostrophe and uses a '.' decimal
+	// separator, as in "1'234.56".
+	LocaleSwiss = Locale{Group: '\'', Decimal: '.'}
+
+	// LocaleAuto detects the convention from the string being parsed. See
+	// AutoLocale.
+	LocaleAuto = AutoLocale('.')
+)
+
+// AutoLocale returns a Locale that detects the digit grouping character and
+// the decimal separator from the string being parsed.
+//
+// Spaces and apostrophes are always treated as digit grouping. If both '.'
+// and ',' appear, the last one is the decimal separator. If only one of them
+// appears more than once, it is the digit grouping character. A single '.' or
+// ',' followed by exactly three digits is ambiguous ("1,234"); it is treated
+// as the decimal separator if it equals decimal and as digit grouping
+// otherwise. Any other single '.' or ',' is the decimal separator.
+func AutoLocale(decimal rune) Locale {
+	return Locale{Decimal: decimal, auto: true}
+}
+
+// IsAuto reports whether l detects the convention from the string being
+// parsed.
+func (l Locale) IsAuto() bool {
+	return l.auto
+}
+
+// decimal returns the decimal separator of l.
+func (l Locale) decimal() rune {
+	if l.Decimal == 0 {
+		return '.'
+	}
+	return l.Decimal
+}
+
+// isGroup reports whether r is a digit grouping character in l.
+func (l Locale) isGroup(r rune) bool {
+	switch l.Group {
+	case 0:
+		return false
+	case ' ':
+		return unicode.IsSpace(r)
+	case '\'':
+		return r == '\'' || r == '’'
+	}
+	return r == l.Group
+}
+
+// normalize rewrites s, which must already be trimmed, into the syntax
+// accepted by the strconv package. Strings that do not look like decimal
+// numbers, such as "NaN" or "0x1p-2", are returned unchanged so that strconv
+// can decide whether to accept them.
+func (l Locale) normalize(s string) (string, bool) {
+	if l.auto {
+		l = l.detect(s)
+	}
+	if l.Group == 0 && l.decimal() == '.' {
+		return s, true
+	}
+	if !looksDecimal(s, l) {
+		return s, true
+	}
+
+	var sb strings.Builder
+	sb.Grow(len(s))
+	rs := []rune(s)
+	i := 0
+	if rs[0] == '+' || rs[0] == '-' {
+		sb.WriteRune(rs[0])
+		i++
+	}
+
+	// Digits in the current group and the number of groups seen so far,
+	// used to validate the placement of grouping characters.
+	run, groups := 0, 0
+	seenDecimal, seenDigit := false, false
+	for ; i < len(rs); i++ {
+		r := rs[i]
+		switch {
+		case r >= '0' && r <= '9':
+			sb.WriteRune(r)
+			run++
+			seenDigit = true
+		case r == l.decimal() && !seenDecimal:
+			if groups > 0 && run != 3 {
+				return "", false
+			}
+			sb.WriteByte('.')
+			seenDecimal = true
+		case l.isGroup(r) && !seenDecimal:
+			// A grouping character must be surrounded by digits, and the
+			// groups that follow the first one must hold two or three
+			// digits, which permits both "1,234,567" and "12,34,567".
+			if run == 0 || i+1 >= len(rs) || rs[i+1] < '0' || rs[i+1] > '9' {
+				return "", false
+			}
+			if groups > 0 && run != 2 && run != 3 {
+				return "", false
+			}
+			if groups == 0 && run > 3 {
+				return "", false
+			}
+			groups++
+			run = 0
+		case (r == 'e' || r == 'E') && seenDigit:
+			if groups > 0 && !seenDecimal && run != 3 {
+				return "", false
+			}
+			sb.WriteString(string(rs[i:]))
+			return sb.String(), true
+		default:
+			return "", false
+		}
+	}
+	if groups > 0 && !seenDecimal && run != 3 {
+		return "", false
+	}
+	return sb.String(), true
+}
+
+// detect returns the concrete Locale that an automatic Locale resolves to
+// for s.
+func (l Locale) detect(s string) Locale {
+	rs := []rune(s)
+	var group rune
+	dots, commas, lastDot, lastComma := 0, 0, -1, -1
+	for i, r := range rs {
+		switch {
+		case r == '.':
+			dots++
+			lastDot = i
+		case r == ',':
+			commas++
+			lastComma = i
+		case r == '\'' || r == '’':
+			group = '\''
+		case unicode.IsSpace(r) && group == 0:
+			group = ' '
+		}
+	}
+
+	decimal := l.decimal()
+	switch {
+	case dots > 0 && commas > 0:
+		decimal = '.'
+		if lastComma > lastDot {
+			decimal = ','
+		}
+	case dots > 1:
+		decimal = ','
+	case commas > 1:
+		decimal = '.'
+	case dots == 1:
+		decimal = '.'
+		if group == 0 && decimal != l.decimal() && ambiguousGroup(rs, lastDot) {
+			decimal = ','
+		}
+	case commas == 1:
+		decimal = ','
+		if group == 0 && decimal != l.decimal() && ambiguousGroup(rs, lastComma) {
+			decimal = '.'
+		}
+	}
+	if group == 0 && dots+commas > 0 {
+		// The separator that is not the decimal separator, if present, is
+		// the grouping character.
+		if decimal == '.' && commas > 0 {
+			group = ','
+		} else if decimal == ',' && dots > 0 {
+			group = '.'
+		}
+	}
+	return Locale{Group: group, Decimal: decimal}
+}
+
+// ambiguousGroup reports whether the separator at index i of rs could be
+// either a decimal separator or digit grouping, as in "1,234".
+func ambiguousGroup(rs []rune, i int) bool {
+	before, after := 0, 0
+	for j := i - 1; j >= 0 && rs[j] >= '0' && rs[j] <= '9'; j-- {
+		before++
+	}
+	for j := i + 1; j < len(rs) && rs[j] >= '0' && rs[j] <= '9'; j++ {
+		after++
+	}
+	if before == 0 || before > 3 || after != 3 || i+4 != len(rs) {
+		return false
+	}
+	// Digit grouping never follows a lone zero.
+	return !(before == 1 && rs[i-1] == '0')
+}
+
+// looksDecimal reports whether s is made up only of signs, digits, exponent
+// markers and the separators of l.
+func looksDecimal(s string, l Locale) bool {
+	hasDigit := false
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			hasDigit = true
+		case r == '+' || r == '-' || r == 'e' || r == 'E' || r == l.decimal() || l.isGroup(r):
+		default:
+			return false
+		}
+	}
+	return hasDigit
+}
@@ -0,0 +1,269 @@
+// Copyright 2019 Miles Barr <milesbarr2@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marshaler
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// A NumberFormat controls how the Robust number types and Decimal parse and
+// format numbers. Its methods, such as ParseFloat and ParseInt, can be called
+// on any NumberFormat value, so code that needs several formats, such as two
+// decoders with different locales, should call them directly rather than
+// change DefaultNumberFormat.
+// The zero value parses numbers like the strconv package, except that
+// integers may be written with a fractional part or an exponent and are
+// rounded half away from zero.
+type NumberFormat struct {
+	// Locale determines the digit grouping character and the decimal
+	// separator.
+	Locale Locale
+
+	// Accounting enables accounting notation when parsing floating-point
+	// numbers. See ParseAccounting.
+	Accounting bool
+
+	// NegativeDebits makes a DR marker, rather than a CR marker, denote a
+	// negative amount in accounting notation. By default CR is negative,
+	// following the debit-positive convention of ledger exports.
+	NegativeDebits bool
+
+	// Suffixes selects the magnitude suffixes, such as the "M" in "1.2M",
+	// that are accepted.
+	Suffixes SuffixMode
+
+	// Rounding selects how the integer types round numbers with a
+	// fractional part, such as "2.5" or "1.25K".
+	Rounding RoundingMode
+
+	// JSONString makes MarshalJSON write numbers as JSON strings rather than
+	// JSON numbers.
+	JSONString bool
+
+	// Fixed makes the floating-point types format values with exactly
+	// Precision digits after the decimal point. Otherwise values are
+	// formatted with the fewest digits that parse back to the same value.
+	Fixed     bool
+	Precision int
+
+	// SentinelPolicy selects how floating-point types handle missing-value
+	// sentinels and non-finite values.
+	SentinelPolicy SentinelPolicy
+
+	// Sentinels are the missing-value sentinels, matched regardless of case.
+	// If nil, DefaultSentinels is used.
+	Sentinels []string
+
+	// NonFinite selects how MarshalJSON writes NaN and infinities.
+	NonFinite NonFinitePolicy
+
+	// DetectBase makes the integer types accept the base prefixes 0x, 0o and
+	// 0b, and underscores between digits, as in Go integer literals. A
+	// leading zero alone does not select base 8.
+	DetectBase bool
+}
+
+// DefaultNumberFormat is the NumberFormat used by the methods of the Robust
+// number types and Decimal. It is not guarded by a lock, so it must be
+// configured before any values are marshaled or unmarshaled and must not be
+// changed while other goroutines use those types.
+var DefaultNumberFormat NumberFormat
+
+// normalize rewrites the trimmed string s into the syntax accepted by the
+// strconv package.
+func (nf NumberFormat) normalize(s string) (string, error) {
+	s, exp := nf.Suffixes.cut(s)
+	n, ok := nf.Locale.normalize(s)
+	if !ok {
+		return "", strconv.ErrSyntax
+	}
+	if exp != 0 {
+		if strings.ContainsAny(n, "eEpPxX") {
+			return "", strconv.ErrSyntax
+		}
+		n += "e" + strconv.Itoa(exp)
+	}
+	return n, nil
+}
+
+// ParseFloat is like strconv.ParseFloat but parses s according to nf.
+// Missing-value sentinels are handled according to nf.SentinelPolicy.
+func (nf NumberFormat) ParseFloat(s string, bitSize int) (float64, error) {
+	if nf.SentinelPolicy != SentinelNone && nf.sentinel(strings.TrimSpace(s)) {
+		switch nf.SentinelPolicy {
+		case SentinelMissing:
+			return 0, numError("ParseFloat", s, ErrMissing)
+		case SentinelNaN:
+			return math.NaN(), nil
+		}
+		return 0, numError("ParseFloat", s, strconv.ErrSyntax)
+	}
+	var f float64
+	var err error
+	if nf.Accounting {
+		f, _, err = nf.ParseAccounting(s, bitSize)
+	} else {
+		f, err = nf.parseFloat(s, bitSize)
+	}
+	if err == nil {
+		if err = nf.checkFinite(f); err != nil {
+			return 0, numError("ParseFloat", s, err)
+		}
+	}
+	return f, err
+}
+
+// parseFloat parses s according to nf, ignoring accounting notation.
+func (nf NumberFormat) parseFloat(s string, bitSize int) (float64, error) {
+	n, err := nf.normalize(s)
+	if err != nil {
+		return 0, numError("ParseFloat", s, err)
+	}
+	return strconv.ParseFloat(n, bitSize)
+}
+
+// ParseInt is like strconv.ParseInt with base 10 but parses s according to
+// nf. If nf.DetectBase is set, the base is taken from the prefix of s. A
+// number with a fractional part or an exponent is rounded according to
+// nf.Rounding, and ErrRange is reported if the result does not fit in
+// bitSize bits.
+func (nf NumberFormat) ParseInt(s string, bitSize int) (int64, error) {
+	if nf.DetectBase {
+		n, base, ok := detectBase(s)
+		if !ok {
+			return 0, numError("ParseInt", s, strconv.ErrSyntax)
+		}
+		if base != 10 {
+			i, err := strconv.ParseInt(n, base, bitSize)
+			if err != nil {
+				return i, numError("ParseInt", s, unwrapNumError(err))
+			}
+			return i, nil
+		}
+		s = n
+	}
+	n, err := nf.normalize(s)
+	if err == nil {
+		n, err = nf.Rounding.roundInteger(n)
+	}
+	if err != nil {
+		return 0, numError("ParseInt", s, err)
+	}
+	i, err := strconv.ParseInt(n, 10, bitSize)
+	if err != nil {
+		return i, numError("ParseInt", s, unwrapNumError(err))
+	}
+	return i, nil
+}
+
+// ParseUint is like strconv.ParseUint with base 10 but parses s according to
+// nf. If nf.DetectBase is set, the base is taken from the prefix of s. A
+// number with a fractional part or an exponent is rounded according to
+// nf.Rounding, and ErrRange is reported if the result does not fit in
+// bitSize bits.
+func (nf NumberFormat) ParseUint(s string, bitSize int) (uint64, error) {
+	if nf.DetectBase {
+		n, base, ok := detectBase(s)
+		if !ok {
+			return 0, numError("ParseUint", s, strconv.ErrSyntax)
+		}
+		if base != 10 {
+			u, err := parseUint(n, base, bitSize)
+			if err != nil {
+				return u, numError("ParseUint", s, unwrapNumError(err))
+			}
+			return u, nil
+		}
+		s = n
+	}
+	n, err := nf.normalize(s)
+	if err == nil {
+		n, err = nf.Rounding.roundInteger(n)
+	}
+	if err != nil {
+		return 0, numError("ParseUint", s, err)
+	}
+	u, err := parseUint(n, 10, bitSize)
+	if err != nil {
+		return u, numError("ParseUint", s, unwrapNumError(err))
+	}
+	return u, nil
+}
+
+// parseUint is like strconv.ParseUint, except that a well-formed negative
+// number other than zero is reported as out of range.
+func parseUint(n string, base, bitSize int) (uint64, error) {
+	if !strings.HasPrefix(n, "-") {
+		return strconv.ParseUint(n, base, bitSize)
+	}
+	u, err := strconv.ParseUint(n[1:], base, bitSize)
+	if err == nil && u == 0 {
+		return 0, nil
+	}
+	if err == nil || unwrapNumError(err) == strconv.ErrRange {
+		return 0, strconv.ErrRange
+	}
+	return 0, err
+}
+
+// FormatFloat formats f, a float of the given bit size, according to nf.
+// Unless nf.Fixed is set, the result is the shortest string that parses back
+// to f, in exponent form only for very large or very small values, as in
+// encoding/json.
+func (nf NumberFormat) FormatFloat(f float64, bitSize int) string {
+	if nf.Fixed {
+		return strconv.FormatFloat(f, 'f', nf.Precision, bitSize)
+	}
+	abs := math.Abs(f)
+	if abs == 0 || abs >= 1e-6 && abs < 1e21 || math.IsNaN(f) || math.IsInf(f, 0) {
+		return strconv.FormatFloat(f, 'f', -1, bitSize)
+	}
+	s := strconv.FormatFloat(f, 'e', -1, bitSize)
+	// Remove the leading zero of a two-digit exponent, turning "1e-07" into
+	// "1e-7".
+	if n := len(s); n >= 4 && s[n-4] == 'e' && s[n-3] == '-' && s[n-2] == '0' {
+		s = s[:n-2] + s[n-1:]
+	}
+	return s
+}
+
+// marshalNumber returns the JSON encoding of the formatted number s, which is
+// a JSON string if nf.JSONString is set and a JSON number otherwise.
+func (nf NumberFormat) marshalNumber(s string) []byte {
+	if nf.JSONString {
+		return []byte(strconv.Quote(s))
+	}
+	return []byte(s)
+}
+
+// parseError returns the error reported by the Set method of the named type
+// when s cannot be parsed.
+func parseError(typ, s string, err error) error {
+	switch unwrapNumError(err) {
+	case strconv.ErrRange:
+		return fmt.Errorf("marshaler.%s.Set: \"%s\" is out of range", typ, s)
+	case ErrFractional:
+		return fmt.Errorf("marshaler.%s.Set: \"%s\" has a fractional part", typ, s)
+	}
+	return fmt.Errorf("marshaler.%s.Set: cannot parse \"%s\"", typ, s)
+}
+
+// numError returns a *strconv.NumError for the given function and input.
+func numError(fn, s string, err error) error {
+	return &strconv.NumError{Func: fn, Num: s, Err: err}
+}
@@ -0,0 +1,103 @@
+// Copyright 2019 Miles Barr <milesbarr2@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marshaler_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/jadefox10200/marshaler"
+)
+
+func TestNumberFormatParseFloat(t *testing.T) {
+	tests := []struct {
+		locale marshaler.Locale
+		in     string
+		want   float64
+		ok     bool
+	}{
+		{marshaler.LocaleC, "1234.56", 1234.56, true},
+		{marshaler.LocaleC, "1,234.56", 0, false},
+		{marshaler.LocaleEnglish, "1,234,567.89", 1234567.89, true},
+		{marshaler.LocaleEnglish, "-1,234", -1234, true},
+		{marshaler.LocaleEnglish, "1,23", 0, false},
+		{marshaler.LocaleEuropean, "1.234.567,89", 1234567.89, true},
+		{marshaler.LocaleEuropean, "0,5", 0.5, true},
+		{marshaler.LocaleFrench, "1 234,5", 1234.5, true},
+		{marshaler.LocaleFrench, "1\u00a0234,5", 1234.5, true},
+		{marshaler.LocaleSwiss, "1'234.5", 1234.5, true},
+		{marshaler.LocaleAuto, "1,234.56", 1234.56, true},
+		{marshaler.LocaleAuto, "1.234,56", 1234.56, true},
+		{marshaler.LocaleAuto, "1,234", 1234, true},
+		{marshaler.AutoLocale(','), "1,234", 1.234, true},
+		{marshaler.LocaleAuto, "1.234.567", 1234567, true},
+	}
+	for _, tt := range tests {
+		nf := marshaler.NumberFormat{Locale: tt.locale}
+		got, err := nf.ParseFloat(tt.in, 64)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("%+v.ParseFloat(%q) = %v, %v; want %v, ok %v", tt.locale, tt.in, got, err, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestNumberFormatParseInt(t *testing.T) {
+	european := marshaler.NumberFormat{Locale: marshaler.LocaleEuropean}
+	tests := []struct {
+		nf   marshaler.NumberFormat
+		in   string
+		want int64
+		ok   bool
+	}{
+		{marshaler.NumberFormat{}, "1234", 1234, true},
+		{marshaler.NumberFormat{}, "1e3", 1000, true},
+		{marshaler.NumberFormat{}, "1,234", 0, false},
+		{marshaler.NumberFormat{Locale: marshaler.LocaleEnglish}, "1,234", 1234, true},
+		{european, "1.234", 1234, true},
+		{european, "1.234,5", 1235, true},
+		{european, "-1.234,5", -1235, true},
+		{marshaler.NumberFormat{Locale: marshaler.LocaleFrench}, "1 234", 1234, true},
+	}
+	for _, tt := range tests {
+		got, err := tt.nf.ParseInt(tt.in, 64)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("%+v.ParseInt(%q) = %v, %v; want %v, ok %v", tt.nf.Locale, tt.in, got, err, tt.want, tt.ok)
+		}
+	}
+}
+
+// TestNumberFormatIndependent checks that NumberFormat values with different
+// locales can be used at the same time without touching DefaultNumberFormat.
+func TestNumberFormatIndependent(t *testing.T) {
+	english := marshaler.NumberFormat{Locale: marshaler.LocaleEnglish}
+	european := marshaler.NumberFormat{Locale: marshaler.LocaleEuropean}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if f, err := english.ParseFloat("1,234.5", 64); err != nil || f != 1234.5 {
+				t.Errorf("english: %v, %v", f, err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if f, err := european.ParseFloat("1.234,5", 64); err != nil || f != 1234.5 {
+				t.Errorf("european: %v, %v", f, err)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,113 @@
+// Copyright 2019 Miles Barr <milesbarr2@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marshaler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A Quarter is a calendar quarter of a particular year. It can be
+// unmarshaled from "2019Q3", "2019-Q3", "2019 q3", "Q3 2019" or "3Q2019" and
+// is marshaled in YYYYQn format.
+type Quarter struct {
+	Year    int
+	Quarter int
+}
+
+// QuarterOf returns the quarter that contains d.
+func QuarterOf(d Date) Quarter {
+	y, m, _ := time.Time(d).Date()
+	return Quarter{y, (int(m)-1)/3 + 1}
+}
+
+// ParseQuarter parses s as a Quarter.
+func ParseQuarter(s string) (Quarter, error) {
+	q, ok := parseQuarter(s)
+	if !ok {
+		return Quarter{}, fmt.Errorf("marshaler.ParseQuarter: cannot parse \"%s\"", s)
+	}
+	return q, nil
+}
+
+func parseQuarter(s string) (Quarter, bool) {
+	s = strings.Map(func(r rune) rune {
+		if r == ' ' || r == '-' || r == '/' {
+			return -1
+		}
+		return r
+	}, strings.ToUpper(s))
+	i := strings.IndexByte(s, 'Q')
+	if i < 0 {
+		return Quarter{}, false
+	}
+	var year, quarter string
+	switch {
+	case i == 4: // 2019Q3
+		year, quarter = s[:i], s[i+1:]
+	case i == 0 && len(s) == 6: // Q32019
+		year, quarter = s[2:], s[1:2]
+	case i == 1 && len(s) == 6: // 3Q2019
+		year, quarter = s[2:], s[:1]
+	default:
+		return Quarter{}, false
+	}
+	if len(year) != 4 || len(quarter) != 1 || !isDigits(year) || quarter < "1" || quarter > "4" {
+		return Quarter{}, false
+	}
+	y, _ := strconv.Atoi(year)
+	return Quarter{y, int(quarter[0] - '0')}, true
+}
+
+// FirstDay returns the first day of q.
+func (q Quarter) FirstDay() Date {
+	return newDate(q.Year, time.Month(3*q.Quarter-2), 1)
+}
+
+// LastDay returns the last day of q.
+func (q Quarter) LastDay() Date {
+	return newDate(q.Year, time.Month(3*q.Quarter+1), 0)
+}
+
+// String implements the flag.Value interface.
+func (q Quarter) String() string {
+	return fmt.Sprintf("%04dQ%d", q.Year, q.Quarter)
+}
+
+// Set implements the flag.Value interface.
+func (q *Quarter) Set(s string) error {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	v, err := ParseQuarter(s)
+	if err != nil {
+		return fmt.Errorf("marshaler.Quarter.Set: cannot parse \"%s\"", s)
+	}
+	*q = v
+	return nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (q Quarter) MarshalText() ([]byte, error) {
+	return []byte(q.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (q *Quarter) UnmarshalText(text []byte) error {
+	return q.Set(string(text))
+}
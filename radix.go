@@ -0,0 +1,88 @@
+// Copyright 2019 Miles Barr <milesbarr2@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marshaler
+
+import (
+	"strconv"
+	"strings"
+)
+
+// detectBase removes a 0x, 0o or 0b base prefix and any underscore digit
+// separators from the integer s. It returns the sign and digits that remain,
+// the base they are written in and whether s was well formed. Unlike
+// strconv.ParseInt with base 0, a leading zero does not select base 8.
+func detectBase(s string) (string, int, bool) {
+	sign := ""
+	if s != "" && (s[0] == '+' || s[0] == '-') {
+		sign, s = s[:1], s[1:]
+	}
+	base := 10
+	if len(s) > 2 && s[0] == '0' {
+		switch s[1] {
+		case 'x', 'X':
+			base = 16
+		case 'o', 'O':
+			base = 8
+		case 'b', 'B':
+			base = 2
+		}
+		if base != 10 {
+			s = s[2:]
+			// As in Go literals, an underscore may follow the prefix.
+			if s[0] == '_' {
+				s = s[1:]
+			}
+		}
+	}
+	if !strings.Contains(s, "_") {
+		return sign + s, base, s != ""
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '_' {
+			sb.WriteByte(s[i])
+			continue
+		}
+		// An underscore must separate two digits.
+		if i == 0 || i == len(s)-1 || !isBaseDigit(s[i-1], base) || !isBaseDigit(s[i+1], base) {
+			return "", 0, false
+		}
+	}
+	return sign + sb.String(), base, true
+}
+
+// isBaseDigit reports whether c is a digit in the given base.
+func isBaseDigit(c byte, base int) bool {
+	var v int
+	switch {
+	case c >= '0' && c <= '9':
+		v = int(c - '0')
+	case c >= 'a' && c <= 'z':
+		v = int(c-'a') + 10
+	case c >= 'A' && c <= 'Z':
+		v = int(c-'A') + 10
+	default:
+		return false
+	}
+	return v < base
+}
+
+// unwrapNumError returns the underlying error of a *strconv.NumError.
+func unwrapNumError(err error) error {
+	if ne, ok := err.(*strconv.NumError); ok {
+		return ne.Err
+	}
+	return err
+}
@@ -0,0 +1,391 @@
+// Copyright 2019 Miles Barr <milesbarr2@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marshaler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// errRelative is reported for a string that is not a relative time
+// expression.
+var errRelative = errors.New("not a relative time expression")
+
+// errRelativeRange is reported for a relative time expression whose offset
+// is too large to represent.
+var errRelativeRange = errors.New("relative time out of range")
+
+// relativeUnits maps the unit names accepted in relative time expressions to
+// their single-letter Grafana spelling.
+var relativeUnits = map[string]byte{
+	"s": 's', "sec": 's', "secs": 's', "second": 's', "seconds": 's',
+	"m": 'm', "min": 'm', "mins": 'm', "minute": 'm', "minutes": 'm',
+	"h": 'h', "hr": 'h', "hrs": 'h', "hour": 'h', "hours": 'h',
+	"d": 'd', "day": 'd', "days": 'd',
+	"w": 'w', "wk": 'w', "wks": 'w', "week": 'w', "weeks": 'w',
+	"mo": 'M', "month": 'M', "months": 'M',
+	"q": 'Q', "quarter": 'Q', "quarters": 'Q',
+	"y": 'y', "yr": 'y', "yrs": 'y', "year": 'y', "years": 'y',
+}
+
+// weekdays maps weekday names and abbreviations to time.Weekday.
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "sun": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday, "tues": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday, "thurs": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+}
+
+// Now returns the current time according to the clock of p.
+func (p *TimeParser) Now() time.Time {
+	p.mu.RLock()
+	clock := p.clock
+	p.mu.RUnlock()
+	if clock == nil {
+		return time.Now()
+	}
+	return clock()
+}
+
+// SetClock sets the clock p uses to resolve relative time expressions. A nil
+// clock restores time.Now. Tests can use a fixed clock to make relative
+// expressions deterministic.
+func (p *TimeParser) SetClock(clock func() time.Time) {
+	p.mu.Lock()
+	p.clock = clock
+	p.mu.Unlock()
+}
+
+// SetRelative sets whether p parses a FlexibleTime written as a relative
+// time expression. Expressions are only tried after the layouts of p.
+func (p *TimeParser) SetRelative(on bool) {
+	p.mu.Lock()
+	p.relative = on
+	p.mu.Unlock()
+}
+
+// relativeTimes reports whether p parses a FlexibleTime written as a
+// relative time expression.
+func (p *TimeParser) relativeTimes() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.relative
+}
+
+// ParseRelative parses a relative time expression against the clock and in
+// the location of p. The accepted expressions are:
+//
+//	now, today, yesterday, tomorrow
+//	3 days ago, an hour ago, in 2 weeks, 90 minutes from now
+//	next friday, last monday, this friday, next week, last month
+//	start of month, end of last quarter, beginning of this year
+//	now-15m, now+1h, now/d, now-1d/d, now-1M/M (Grafana style)
+//
+// Weeks start on Monday. Days, weeks, months, quarters and years are
+// calendar units, and expressions naming a day, such as "yesterday" or
+// "next friday", resolve to the start of that day. An end of a period is its
+// last nanosecond.
+func (p *TimeParser) ParseRelative(s string) (time.Time, error) {
+	now := p.Now().In(p.Location())
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "now") && len(s) > 3 && strings.ContainsRune("+-/", rune(s[3])) {
+		return parseGrafana(s[3:], now)
+	}
+	return parseRelativePhrase(strings.Fields(strings.ToLower(s)), now)
+}
+
+// parseGrafana parses the operations that follow "now" in a Grafana-style
+// expression, such as "-1d/d". Unit letters are case sensitive: m is a
+// minute and M a month.
+func parseGrafana(s string, t time.Time) (time.Time, error) {
+	for s != "" {
+		op := s[0]
+		s = s[1:]
+		i := 0
+		for i < len(s) && isDigitByte(s[i]) {
+			i++
+		}
+		if op == '/' && i > 0 || i >= len(s) {
+			return time.Time{}, errRelative
+		}
+		n := 1
+		if i > 0 {
+			var err error
+			if n, err = strconv.Atoi(s[:i]); err != nil {
+				return time.Time{}, errRelative
+			}
+		}
+		unit := s[i]
+		if !strings.ContainsRune("smhdwMQy", rune(unit)) {
+			return time.Time{}, errRelative
+		}
+		if !unitsInRange(unit, n) {
+			return time.Time{}, errRelativeRange
+		}
+		s = s[i+1:]
+		switch op {
+		case '+':
+			t = addUnits(t, unit, n)
+		case '-':
+			t = addUnits(t, unit, -n)
+		case '/':
+			t = startOf(t, unit)
+		default:
+			return time.Time{}, errRelative
+		}
+	}
+	return t, nil
+}
+
+// parseRelativePhrase parses a relative time expression written in words.
+func parseRelativePhrase(f []string, now time.Time) (time.Time, error) {
+	today := startOf(now, 'd')
+	switch len(f) {
+	case 1:
+		switch f[0] {
+		case "now":
+			return now, nil
+		case "today":
+			return today, nil
+		case "yesterday":
+			return today.AddDate(0, 0, -1), nil
+		case "tomorrow":
+			return today.AddDate(0, 0, 1), nil
+		}
+	case 2:
+		// next friday, last month, this week
+		if n, ok := map[string]int{"next": 1, "last": -1, "this": 0}[f[0]]; ok {
+			if wd, ok := weekdays[f[1]]; ok {
+				return weekdayFrom(today, wd, n), nil
+			}
+			if unit, ok := relativeUnits[f[1]]; ok && unit != 's' {
+				return addUnits(startOf(now, unit), unit, n), nil
+			}
+		}
+	}
+	// 3 days ago, in 2 weeks, 90 minutes from now
+	var count, name string
+	sign := 1
+	switch {
+	case len(f) == 3 && f[2] == "ago":
+		count, name, sign = f[0], f[1], -1
+	case len(f) == 3 && f[0] == "in":
+		count, name = f[1], f[2]
+	case len(f) == 4 && f[2] == "from" && f[3] == "now":
+		count, name = f[0], f[1]
+	}
+	if n, unit, ok := quantity(count, name); ok {
+		if !unitsInRange(unit, n) {
+			return time.Time{}, errRelativeRange
+		}
+		return addUnits(now, unit, sign*n), nil
+	}
+	// start of month, end of last quarter
+	if len(f) >= 3 && f[1] == "of" && len(f) <= 4 {
+		end := false
+		switch f[0] {
+		case "start", "beginning":
+		case "end":
+			end = true
+		default:
+			return time.Time{}, errRelative
+		}
+		t, rest := now, f[2:]
+		if len(rest) == 2 {
+			n, ok := map[string]int{"next": 1, "last": -1, "this": 0}[rest[0]]
+			if !ok {
+				return time.Time{}, errRelative
+			}
+			unit, ok := relativeUnits[rest[1]]
+			if !ok {
+				return time.Time{}, errRelative
+			}
+			t, rest = addUnits(startOf(t, unit), unit, n), rest[1:]
+		}
+		unit, ok := relativeUnits[rest[0]]
+		if !ok || unit == 's' {
+			return time.Time{}, errRelative
+		}
+		if end {
+			return addUnits(startOf(t, unit), unit, 1).Add(-time.Nanosecond), nil
+		}
+		return startOf(t, unit), nil
+	}
+	return time.Time{}, errRelative
+}
+
+// quantity parses a count, which may be "a" or "an", and a unit name.
+func quantity(count, unit string) (int, byte, bool) {
+	u, ok := relativeUnits[unit]
+	if !ok {
+		return 0, 0, false
+	}
+	if count == "a" || count == "an" {
+		return 1, u, true
+	}
+	n, err := strconv.Atoi(count)
+	if err != nil || n < 0 {
+		return 0, 0, false
+	}
+	return n, u, true
+}
+
+// unitsInRange reports whether n of the given unit can be added to a time
+// without overflow: the offset must fit in a time.Duration for seconds,
+// minutes and hours, and n must fit in an int32 for calendar units.
+func unitsInRange(unit byte, n int) bool {
+	var d time.Duration
+	switch unit {
+	case 's':
+		d = time.Second
+	case 'm':
+		d = time.Minute
+	case 'h':
+		d = time.Hour
+	default:
+		return n >= math.MinInt32 && n <= math.MaxInt32
+	}
+	return int64(n) >= math.MinInt64/int64(d) && int64(n) <= math.MaxInt64/int64(d)
+}
+
+// addUnits adds n of the given unit to t. Adding months, quarters or years
+// keeps the day of the month where it exists and otherwise moves it to the
+// last day of the month, so one month before March 31 is February 28 or 29.
+// The caller must check that n is within range with unitsInRange.
+func addUnits(t time.Time, unit byte, n int) time.Time {
+	switch unit {
+	case 's':
+		return t.Add(time.Duration(n) * time.Second)
+	case 'm':
+		return t.Add(time.Duration(n) * time.Minute)
+	case 'h':
+		return t.Add(time.Duration(n) * time.Hour)
+	case 'd':
+		return t.AddDate(0, 0, n)
+	case 'w':
+		return t.AddDate(0, 0, 7*n)
+	case 'M':
+		return time.Time(Date(t).AddMonths(n))
+	case 'Q':
+		return time.Time(Date(t).AddMonths(3 * n))
+	}
+	return time.Time(Date(t).AddMonths(12 * n))
+}
+
+// startOf returns the start of the given unit of time that contains t.
+func startOf(t time.Time, unit byte) time.Time {
+	y, m, d := t.Date()
+	loc := t.Location()
+	switch unit {
+	case 's':
+		return time.Date(y, m, d, t.Hour(), t.Minute(), t.Second(), 0, loc)
+	case 'm':
+		return time.Date(y, m, d, t.Hour(), t.Minute(), 0, 0, loc)
+	case 'h':
+		return time.Date(y, m, d, t.Hour(), 0, 0, 0, loc)
+	case 'd':
+		return time.Date(y, m, d, 0, 0, 0, 0, loc)
+	case 'w':
+		// Weeks start on Monday.
+		offset := (int(t.Weekday()) + 6) % 7
+		return time.Date(y, m, d-offset, 0, 0, 0, 0, loc)
+	case 'M':
+		return time.Date(y, m, 1, 0, 0, 0, 0, loc)
+	case 'Q':
+		return time.Date(y, m-(m-1)%3, 1, 0, 0, 0, 0, loc)
+	}
+	return time.Date(y, time.January, 1, 0, 0, 0, 0, loc)
+}
+
+// weekdayFrom returns the day with weekday wd that is after today if n is 1,
+// before today if n is -1, and in the week of today if n is 0.
+func weekdayFrom(today time.Time, wd time.Weekday, n int) time.Time {
+	diff := int(wd - today.Weekday())
+	switch n {
+	case 1:
+		if diff <= 0 {
+			diff += 7
+		}
+	case -1:
+		if diff >= 0 {
+			diff -= 7
+		}
+	default:
+		monday := startOf(today, 'w')
+		return monday.AddDate(0, 0, (int(wd)+6)%7)
+	}
+	return today.AddDate(0, 0, diff)
+}
+
+// A RelativeTime is a time.Time that can be unmarshaled from a relative time
+// expression, such as "yesterday", "3 days ago" or "now-15m", resolved
+// against the clock of DefaultTimeParser. See TimeParser.ParseRelative for
+// the accepted expressions. Absolute times are parsed like a FlexibleTime.
+// A RelativeTime is marshaled in RFC 3339 format.
+type RelativeTime time.Time
+
+// String implements the flag.Value interface.
+func (rt RelativeTime) String() string {
+	return time.Time(rt).String()
+}
+
+// Set implements the flag.Value interface.
+func (rt *RelativeTime) Set(s string) error {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	t, err := DefaultTimeParser.ParseRelative(s)
+	if err != nil {
+		t, err = DefaultTimeParser.parseFlexible(s, DefaultTimeParser.Location())
+	}
+	if err != nil {
+		return fmt.Errorf("marshaler.RelativeTime.Set: cannot parse \"%s\"", s)
+	}
+	*rt = RelativeTime(t)
+	return nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (rt RelativeTime) MarshalText() ([]byte, error) {
+	return []byte(time.Time(rt).Format(time.RFC3339Nano)), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (rt *RelativeTime) UnmarshalText(text []byte) error {
+	return rt.Set(string(text))
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (rt *RelativeTime) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		b = []byte(s)
+	}
+	return rt.UnmarshalText(b)
+}
+
+// Format wraps time.Time.Format.
+func (rt RelativeTime) Format(layout string) string {
+	return time.Time(rt).Format(layout)
+}
@@ -0,0 +1,94 @@
+// Copyright 2019 Miles Barr <milesbarr2@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marshaler_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jadefox10200/marshaler"
+)
+
+func TestParseRelative(t *testing.T) {
+	tests := []struct {
+		now, in, want string
+	}{
+		// A Wednesday.
+		{"2019-07-17T13:45:30Z", "now", "2019-07-17T13:45:30Z"},
+		{"2019-07-17T13:45:30Z", "yesterday", "2019-07-16T00:00:00Z"},
+		{"2019-07-17T13:45:30Z", "3 days ago", "2019-07-14T13:45:30Z"},
+		{"2019-07-17T13:45:30Z", "an hour ago", "2019-07-17T12:45:30Z"},
+		{"2019-07-17T13:45:30Z", "in 2 weeks", "2019-07-31T13:45:30Z"},
+		{"2019-07-17T13:45:30Z", "90 minutes from now", "2019-07-17T15:15:30Z"},
+		{"2019-07-17T13:45:30Z", "next friday", "2019-07-19T00:00:00Z"},
+		{"2019-07-17T13:45:30Z", "last wednesday", "2019-07-10T00:00:00Z"},
+		{"2019-07-17T13:45:30Z", "this monday", "2019-07-15T00:00:00Z"},
+		{"2019-07-17T13:45:30Z", "next week", "2019-07-22T00:00:00Z"},
+		{"2019-07-17T13:45:30Z", "start of month", "2019-07-01T00:00:00Z"},
+		{"2019-07-17T13:45:30Z", "Start of Year", "2019-01-01T00:00:00Z"},
+		{"2019-07-17T13:45:30Z", "end of last quarter", "2019-06-30T23:59:59.999999999Z"},
+		{"2019-07-17T13:45:30Z", "now-15m", "2019-07-17T13:30:30Z"},
+		{"2019-07-17T13:45:30Z", "now/d", "2019-07-17T00:00:00Z"},
+		// Month ends.
+		{"2021-03-31T12:00:00Z", "last month", "2021-02-01T00:00:00Z"},
+		{"2021-03-31T12:00:00Z", "next month", "2021-04-01T00:00:00Z"},
+		{"2021-01-31T12:00:00Z", "next month", "2021-02-01T00:00:00Z"},
+		{"2021-03-31T12:00:00Z", "end of last month", "2021-02-28T23:59:59.999999999Z"},
+		{"2021-03-31T12:00:00Z", "start of next month", "2021-04-01T00:00:00Z"},
+		{"2021-03-31T12:00:00Z", "a month ago", "2021-02-28T12:00:00Z"},
+		{"2020-03-31T12:00:00Z", "a month ago", "2020-02-29T12:00:00Z"},
+		{"2021-03-31T12:00:00Z", "now-1M/M", "2021-02-01T00:00:00Z"},
+		{"2021-03-31T12:00:00Z", "now-1M", "2021-02-28T12:00:00Z"},
+		{"2021-05-31T12:00:00Z", "now+1M/M", "2021-06-01T00:00:00Z"},
+		// Quarter and year ends.
+		{"2021-05-31T12:00:00Z", "last quarter", "2021-01-01T00:00:00Z"},
+		{"2021-08-31T12:00:00Z", "next quarter", "2021-10-01T00:00:00Z"},
+		{"2021-08-31T12:00:00Z", "end of last quarter", "2021-06-30T23:59:59.999999999Z"},
+		{"2021-05-31T12:00:00Z", "now-1Q/Q", "2021-01-01T00:00:00Z"},
+		{"2021-05-31T12:00:00Z", "now-1Q", "2021-02-28T12:00:00Z"},
+		{"2020-02-29T12:00:00Z", "now+1y", "2021-02-28T12:00:00Z"},
+		{"2020-02-29T12:00:00Z", "next year", "2021-01-01T00:00:00Z"},
+		// Large offsets within range.
+		{"2019-07-17T13:45:30Z", "now-2000000h", "1791-05-20T05:45:30Z"},
+		{"2019-07-17T13:45:30Z", "in 1000 years", "3019-07-17T13:45:30Z"},
+	}
+	for _, tt := range tests {
+		now, err := time.Parse(time.RFC3339, tt.now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p := &marshaler.TimeParser{}
+		p.SetClock(func() time.Time { return now })
+		got, err := p.ParseRelative(tt.in)
+		if err != nil {
+			t.Errorf("%s: ParseRelative(%q): %v", tt.now, tt.in, err)
+			continue
+		}
+		if got.Format(time.RFC3339Nano) != tt.want {
+			t.Errorf("%s: ParseRelative(%q) = %s, want %s", tt.now, tt.in, got.Format(time.RFC3339Nano), tt.want)
+		}
+	}
+	p := &marshaler.TimeParser{}
+	for _, in := range []string{
+		"", "now/5d", "next second", "3 fortnights ago", "now-", "end of",
+		// Offsets that overflow.
+		"now-3000000h", "now+9223372036854775807s", "now-200000000m",
+		"3000000 hours ago", "in 99999999999 days", "now+9223372036854775807y",
+	} {
+		if _, err := p.ParseRelative(in); err == nil {
+			t.Errorf("ParseRelative(%q): want error", in)
+		}
+	}
+}
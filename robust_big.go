@@ -0,0 +1,190 @@
+// Copyright 2019 Miles Barr <milesbarr2@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marshaler
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// ParseBigInt parses s as an arbitrary-precision integer according to nf.
+// A number with a fractional part or an exponent is rounded according to
+// nf.Rounding.
+func (nf NumberFormat) ParseBigInt(s string) (*big.Int, error) {
+	n, base := s, 10
+	if nf.DetectBase {
+		var ok bool
+		if n, base, ok = detectBase(s); !ok {
+			return nil, numError("ParseBigInt", s, strconv.ErrSyntax)
+		}
+	}
+	if base == 10 {
+		var err error
+		if n, err = nf.normalize(n); err == nil {
+			n, err = nf.Rounding.roundInteger(n)
+		}
+		if err != nil {
+			return nil, numError("ParseBigInt", s, err)
+		}
+	}
+	i, ok := new(big.Int).SetString(n, base)
+	if !ok {
+		return nil, numError("ParseBigInt", s, strconv.ErrSyntax)
+	}
+	return i, nil
+}
+
+// A RobustBigInt is a big.Int that can be unmarshaled from a string or a JSON
+// number of any size. Use (*big.Int)(x) to convert a *RobustBigInt x to a
+// *big.Int.
+type RobustBigInt big.Int
+
+// Int returns ri as a *big.Int.
+func (ri *RobustBigInt) Int() *big.Int {
+	return (*big.Int)(ri)
+}
+
+// String implements the flag.Value interface.
+func (ri *RobustBigInt) String() string {
+	return ri.Int().String()
+}
+
+// Set implements the flag.Value interface.
+func (ri *RobustBigInt) Set(s string) error {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	i, err := DefaultNumberFormat.ParseBigInt(s)
+	if err != nil {
+		return parseError("RobustBigInt", s, err)
+	}
+	ri.Int().Set(i)
+	return nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (ri *RobustBigInt) MarshalText() ([]byte, error) {
+	return []byte(ri.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (ri *RobustBigInt) UnmarshalText(text []byte) error {
+	return ri.Set(string(text))
+}
+
+// MarshalJSON implements the json.Marshaler interface. The value is written
+// as a JSON number, or as a JSON string if DefaultNumberFormat.JSONString is
+// set.
+func (ri *RobustBigInt) MarshalJSON() ([]byte, error) {
+	return DefaultNumberFormat.marshalNumber(ri.String()), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (ri *RobustBigInt) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		b = []byte(s)
+	}
+	return ri.UnmarshalText(b)
+}
+
+// ParseBigFloat parses s as an arbitrary-precision float according to nf.
+// The precision of the result is large enough to hold every digit of s.
+func (nf NumberFormat) ParseBigFloat(s string) (*big.Float, error) {
+	n := s
+	if nf.Accounting {
+		var ok bool
+		if n, _, ok = nf.stripAccounting(strings.TrimSpace(s)); !ok {
+			return nil, numError("ParseBigFloat", s, strconv.ErrSyntax)
+		}
+	}
+	n, err := nf.normalize(n)
+	if err != nil {
+		return nil, numError("ParseBigFloat", s, err)
+	}
+	// Each decimal digit needs log2(10) < 4 bits.
+	prec := uint(4 * len(n))
+	if prec < 64 {
+		prec = 64
+	}
+	f, _, err := big.ParseFloat(n, 10, prec, big.ToNearestEven)
+	if err != nil {
+		return nil, numError("ParseBigFloat", s, strconv.ErrSyntax)
+	}
+	return f, nil
+}
+
+// A RobustBigFloat is a big.Float that can be unmarshaled from a string or a
+// JSON number without loss of precision. Use (*big.Float)(x) to convert a
+// *RobustBigFloat x to a *big.Float.
+type RobustBigFloat big.Float
+
+// Float returns rf as a *big.Float.
+func (rf *RobustBigFloat) Float() *big.Float {
+	return (*big.Float)(rf)
+}
+
+// String implements the flag.Value interface. It formats rf with the
+// smallest number of digits needed to represent it exactly at its precision.
+func (rf *RobustBigFloat) String() string {
+	return rf.Float().Text('g', -1)
+}
+
+// Set implements the flag.Value interface.
+func (rf *RobustBigFloat) Set(s string) error {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	f, err := DefaultNumberFormat.ParseBigFloat(s)
+	if err != nil {
+		return fmt.Errorf("marshaler.RobustBigFloat.Set: cannot parse \"%s\"", s)
+	}
+	rf.Float().SetPrec(f.Prec()).Set(f)
+	return nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (rf *RobustBigFloat) MarshalText() ([]byte, error) {
+	return []byte(rf.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (rf *RobustBigFloat) UnmarshalText(text []byte) error {
+	return rf.Set(string(text))
+}
+
+// MarshalJSON implements the json.Marshaler interface. The value is written
+// as a JSON number, or as a JSON string if DefaultNumberFormat.JSONString is
+// set. Infinities cannot be written as JSON numbers.
+func (rf *RobustBigFloat) MarshalJSON() ([]byte, error) {
+	if rf.Float().IsInf() && !DefaultNumberFormat.JSONString {
+		return nil, fmt.Errorf("marshaler.RobustBigFloat.MarshalJSON: unsupported value %s", rf)
+	}
+	return DefaultNumberFormat.marshalNumber(rf.String()), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (rf *RobustBigFloat) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		b = []byte(s)
+	}
+	return rf.UnmarshalText(b)
+}
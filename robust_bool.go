@@ -0,0 +1,127 @@
+// Copyright 2019 Miles Barr <milesbarr2@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marshaler
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// A BoolFormat controls how strings are parsed by RobustBool.
+type BoolFormat struct {
+	// True and False are the spellings of true and false.
+	True  []string
+	False []string
+
+	// Strict requires an exact, case-sensitive match with one of the
+	// spellings. Otherwise spellings are matched regardless of case, and
+	// any other number equal to 0 or 1, such as "1.0" or "+0", is accepted.
+	Strict bool
+
+	// JSONString makes MarshalJSON write values as the JSON strings "true"
+	// and "false" rather than JSON bools.
+	JSONString bool
+}
+
+// DefaultBoolFormat is the BoolFormat used by the methods of RobustBool. It
+// should be configured before any values are unmarshaled.
+var DefaultBoolFormat = BoolFormat{
+	True:  []string{"true", "t", "yes", "y", "on", "1"},
+	False: []string{"false", "f", "no", "n", "off", "0"},
+}
+
+// ParseBool parses s according to bf.
+func (bf BoolFormat) ParseBool(s string) (bool, error) {
+	match := strings.EqualFold
+	if bf.Strict {
+		match = func(a, b string) bool { return a == b }
+	}
+	for _, t := range bf.True {
+		if match(s, t) {
+			return true, nil
+		}
+	}
+	for _, f := range bf.False {
+		if match(s, f) {
+			return false, nil
+		}
+	}
+	if !bf.Strict {
+		if f, err := strconv.ParseFloat(s, 64); err == nil && (f == 0 || f == 1) {
+			return f == 1, nil
+		}
+	}
+	return false, &strconv.NumError{Func: "ParseBool", Num: s, Err: strconv.ErrSyntax}
+}
+
+// A RobustBool is a bool that can be unmarshaled from the many spellings of
+// true and false found in real-world data, such as "Y", "no", "on" or 1.
+type RobustBool bool
+
+// String implements the flag.Value interface.
+func (rb RobustBool) String() string {
+	return strconv.FormatBool(bool(rb))
+}
+
+// Set implements the flag.Value interface.
+func (rb *RobustBool) Set(s string) error {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	b, err := DefaultBoolFormat.ParseBool(s)
+	if err != nil {
+		return fmt.Errorf("marshaler.RobustBool.Set: cannot parse \"%s\"", s)
+	}
+	*rb = RobustBool(b)
+	return nil
+}
+
+// IsBoolFlag allows a RobustBool flag to be set without a value, like the
+// flags defined by flag.Bool.
+func (rb RobustBool) IsBoolFlag() bool {
+	return true
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (rb RobustBool) MarshalText() ([]byte, error) {
+	return []byte(rb.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (rb *RobustBool) UnmarshalText(text []byte) error {
+	return rb.Set(string(text))
+}
+
+// MarshalJSON implements the json.Marshaler interface. The value is written
+// as a JSON bool, or as a JSON string if DefaultBoolFormat.JSONString is set.
+func (rb RobustBool) MarshalJSON() ([]byte, error) {
+	if DefaultBoolFormat.JSONString {
+		return []byte(strconv.Quote(rb.String())), nil
+	}
+	return []byte(rb.String()), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. The value may be
+// a JSON bool, number or string.
+func (rb *RobustBool) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		b = []byte(s)
+	}
+	return rb.UnmarshalText(b)
+}
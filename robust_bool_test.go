@@ -0,0 +1,83 @@
+// Copyright 2019 Miles Barr <milesbarr2@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marshaler_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jadefox10200/marshaler"
+)
+
+func TestRobustBoolSet(t *testing.T) {
+	tests := []struct {
+		in   string
+		want bool
+		ok   bool
+	}{
+		{"true", true, true},
+		{"YES", true, true},
+		{"Off", false, true},
+		{"1", true, true},
+		{"0", false, true},
+		{"1.0", true, true},
+		{"+0", false, true},
+		{"-0.0", false, true},
+		{"2", false, false},
+		{"-1", false, false},
+		{"0.0001", false, false},
+		{"Inf", false, false},
+		{"NaN", false, false},
+		{"maybe", false, false},
+	}
+	for _, tt := range tests {
+		var b marshaler.RobustBool
+		err := b.Set(tt.in)
+		if (err == nil) != tt.ok || bool(b) != tt.want {
+			t.Errorf("Set(%q) = %v, %v; want %v, ok %v", tt.in, b, err, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestBoolFormatStrict(t *testing.T) {
+	bf := marshaler.BoolFormat{True: []string{"Y"}, False: []string{"N"}, Strict: true}
+	if b, err := bf.ParseBool("Y"); err != nil || !b {
+		t.Errorf("ParseBool(Y) = %v, %v", b, err)
+	}
+	for _, s := range []string{"y", "1", "true"} {
+		if _, err := bf.ParseBool(s); err == nil {
+			t.Errorf("ParseBool(%q) succeeded in strict mode", s)
+		}
+	}
+}
+
+func TestRobustBoolMarshalJSON(t *testing.T) {
+	v := []marshaler.RobustBool{true, false}
+	b, err := json.Marshal(v)
+	if err != nil || string(b) != `[true,false]` {
+		t.Errorf("Marshal = %s, %v", b, err)
+	}
+
+	defer func(bf marshaler.BoolFormat) { marshaler.DefaultBoolFormat = bf }(marshaler.DefaultBoolFormat)
+	marshaler.DefaultBoolFormat.JSONString = true
+	b, err = json.Marshal(v)
+	if err != nil || string(b) != `["true","false"]` {
+		t.Errorf("Marshal with JSONString = %s, %v", b, err)
+	}
+	var got []marshaler.RobustBool
+	if err := json.Unmarshal(b, &got); err != nil || got[0] != true || got[1] != false {
+		t.Errorf("Unmarshal(%s) = %v, %v", b, got, err)
+	}
+}
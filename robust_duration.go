@@ -0,0 +1,343 @@
+// Copyright 2019 Miles Barr <milesbarr2@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marshaler
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A DurationStyle is a canonical form in which durations are formatted.
+type DurationStyle int
+
+const (
+	// DurationGo formats durations like time.Duration.String, as in
+	// "1h30m0s".
+	DurationGo DurationStyle = iota
+
+	// DurationISO formats durations in ISO 8601 format, as in "PT1H30M".
+	DurationISO
+)
+
+// A DurationFormat controls how durations are parsed and formatted by
+// RobustDuration. The zero value formats durations like time.Duration and
+// reads bare numbers as seconds.
+type DurationFormat struct {
+	// Style is the form in which durations are formatted.
+	Style DurationStyle
+
+	// Unit is the unit of a duration written as a bare number. If zero,
+	// bare numbers are seconds.
+	Unit time.Duration
+}
+
+// DefaultDurationFormat is the DurationFormat used by the methods of
+// RobustDuration. It should be configured before any values are unmarshaled.
+var DefaultDurationFormat DurationFormat
+
+// durationUnits maps the unit names accepted in durations to their length.
+var durationUnits = map[string]time.Duration{
+	"ns": time.Nanosecond, "nanosecond": time.Nanosecond, "nanoseconds": time.Nanosecond,
+	"us": time.Microsecond, "µs": time.Microsecond, "μs": time.Microsecond,
+	"microsecond": time.Microsecond, "microseconds": time.Microsecond,
+	"ms": time.Millisecond, "millisecond": time.Millisecond, "milliseconds": time.Millisecond,
+	"s": time.Second, "sec": time.Second, "secs": time.Second,
+	"second": time.Second, "seconds": time.Second,
+	"m": time.Minute, "min": time.Minute, "mins": time.Minute,
+	"minute": time.Minute, "minutes": time.Minute,
+	"h": time.Hour, "hr": time.Hour, "hrs": time.Hour,
+	"hour": time.Hour, "hours": time.Hour,
+	"d": 24 * time.Hour, "day": 24 * time.Hour, "days": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour, "wk": 7 * 24 * time.Hour, "wks": 7 * 24 * time.Hour,
+	"week": 7 * 24 * time.Hour, "weeks": 7 * 24 * time.Hour,
+}
+
+// ParseDuration parses s according to df. The accepted forms are:
+//
+//	1h30m, 1.5h, 300ms (Go)
+//	PT1H30M, P1DT2H, -PT0.5S (ISO 8601)
+//	90 minutes, 1.5 hours, 1 hour and 30 minutes, 2 days, 3 hrs
+//	90, 1.5 (bare numbers in units of df.Unit)
+//
+// Days are 24 hours and weeks are 7 days. ISO 8601 years and months have no
+// fixed length and are rejected unless they are zero.
+func (df DurationFormat) ParseDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	unit := df.Unit
+	if unit == 0 {
+		unit = time.Second
+	}
+	var (
+		ns  *big.Int
+		err error
+	)
+	if _, _, _, ok := splitDecimal(s); ok {
+		ns, err = durationNumber(s, unit)
+	} else if strings.HasPrefix(strings.TrimLeft(s, "+-"), "P") {
+		ns, err = parseISODuration(s)
+	} else {
+		ns, err = parseHumanDuration(strings.ToLower(s))
+	}
+	if err != nil {
+		return 0, numError("ParseDuration", s, err)
+	}
+	if !ns.IsInt64() {
+		return 0, numError("ParseDuration", s, strconv.ErrRange)
+	}
+	return time.Duration(ns.Int64()), nil
+}
+
+// durationNumber returns the decimal number n of the given unit in
+// nanoseconds, rounded half away from zero.
+func durationNumber(n string, unit time.Duration) (*big.Int, error) {
+	neg, digits, point, ok := splitDecimal(n)
+	if !ok {
+		return nil, strconv.ErrSyntax
+	}
+	trimmed := strings.TrimLeft(digits, "0")
+	digits, point = trimmed, point-(len(digits)-len(trimmed))
+	// Avoid building huge numbers for exponents that are out of range anyway.
+	if digits != "" && point > 30 {
+		return nil, strconv.ErrRange
+	}
+	if point > len(digits) {
+		digits += strings.Repeat("0", point-len(digits))
+	}
+	u, _ := new(big.Int).SetString(digits, 10)
+	if neg {
+		u.Neg(u)
+	}
+	d := newDecimal(u, int32(len(digits)-point)).Mul(NewDecimal(int64(unit), 0))
+	d, err := d.Round(0, RoundHalfAway)
+	if err != nil {
+		return nil, err
+	}
+	return d.int(), nil
+}
+
+// parseISODuration parses an ISO 8601 duration, such as "P1DT2H30M", as
+// nanoseconds. A decimal comma is accepted in place of a decimal point.
+func parseISODuration(s string) (*big.Int, error) {
+	neg := false
+	if s[0] == '+' || s[0] == '-' {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
+	s = strings.Replace(s[1:], ",", ".", -1)
+	total := new(big.Int)
+	designators, timePart, n := "YMWD", false, 0
+	for s != "" {
+		if s[0] == 'T' && !timePart {
+			designators, timePart, s = "HMS", true, s[1:]
+			if s == "" {
+				return nil, strconv.ErrSyntax
+			}
+			continue
+		}
+		i := 0
+		for i < len(s) && (isDigitByte(s[i]) || s[i] == '.') {
+			i++
+		}
+		if i == 0 || i == len(s) {
+			return nil, strconv.ErrSyntax
+		}
+		// Designators must appear in order, each at most once.
+		j := strings.IndexByte(designators, s[i])
+		if j < 0 {
+			return nil, strconv.ErrSyntax
+		}
+		var unit time.Duration
+		switch designators[j] {
+		case 'Y', 'M':
+			if !timePart {
+				if _, digits, _, ok := splitDecimal(s[:i]); !ok || strings.Trim(digits, "0") != "" {
+					return nil, strconv.ErrSyntax
+				}
+				break
+			}
+			unit = time.Minute
+		case 'W':
+			unit = 7 * 24 * time.Hour
+		case 'D':
+			unit = 24 * time.Hour
+		case 'H':
+			unit = time.Hour
+		case 'S':
+			unit = time.Second
+		}
+		if unit != 0 {
+			ns, err := durationNumber(s[:i], unit)
+			if err != nil {
+				return nil, err
+			}
+			total.Add(total, ns)
+		}
+		designators, s = designators[j+1:], s[i+1:]
+		n++
+	}
+	if n == 0 {
+		return nil, strconv.ErrSyntax
+	}
+	if neg {
+		total.Neg(total)
+	}
+	return total, nil
+}
+
+// parseHumanDuration parses a duration written as a sequence of numbers and
+// units, such as "1h30m" or "1 hour and 30 minutes", as nanoseconds.
+func parseHumanDuration(s string) (*big.Int, error) {
+	neg := false
+	if s != "" && (s[0] == '+' || s[0] == '-') {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
+	total := new(big.Int)
+	n := 0
+	for {
+		s = strings.TrimLeft(s, " \t,")
+		if strings.HasPrefix(s, "and ") && n > 0 {
+			s = strings.TrimLeft(s[4:], " \t")
+		}
+		if s == "" {
+			break
+		}
+		i := 0
+		for i < len(s) && (isDigitByte(s[i]) || s[i] == '.') {
+			i++
+		}
+		number := s[:i]
+		s = strings.TrimLeft(s[i:], " \t")
+		i = 0
+		for i < len(s) && !isDigitByte(s[i]) && !strings.ContainsRune(" \t,.", rune(s[i])) {
+			i++
+		}
+		unit, ok := durationUnits[s[:i]]
+		if number == "" || !ok {
+			return nil, strconv.ErrSyntax
+		}
+		ns, err := durationNumber(number, unit)
+		if err != nil {
+			return nil, err
+		}
+		total.Add(total, ns)
+		s = s[i:]
+		n++
+	}
+	if n == 0 {
+		return nil, strconv.ErrSyntax
+	}
+	if neg {
+		total.Neg(total)
+	}
+	return total, nil
+}
+
+// FormatDuration formats d in the style of df.
+func (df DurationFormat) FormatDuration(d time.Duration) string {
+	if df.Style == DurationISO {
+		return formatISODuration(d)
+	}
+	return d.String()
+}
+
+// formatISODuration formats d as an ISO 8601 duration in hours, minutes and
+// seconds, such as "PT26H3M0.5S". Days are not used since they are not
+// always 24 hours long.
+func formatISODuration(d time.Duration) string {
+	if d == 0 {
+		return "PT0S"
+	}
+	var b strings.Builder
+	u := uint64(d)
+	if d < 0 {
+		b.WriteByte('-')
+		u = -u
+	}
+	b.WriteString("PT")
+	if h := u / uint64(time.Hour); h > 0 {
+		b.WriteString(strconv.FormatUint(h, 10) + "H")
+		u -= h * uint64(time.Hour)
+	}
+	if m := u / uint64(time.Minute); m > 0 {
+		b.WriteString(strconv.FormatUint(m, 10) + "M")
+		u -= m * uint64(time.Minute)
+	}
+	if u > 0 {
+		sec, frac := u/uint64(time.Second), u%uint64(time.Second)
+		b.WriteString(strconv.FormatUint(sec, 10))
+		if frac > 0 {
+			b.WriteString(strings.TrimRight(fmt.Sprintf(".%09d", frac), "0"))
+		}
+		b.WriteByte('S')
+	}
+	return b.String()
+}
+
+// A RobustDuration is a time.Duration that can be unmarshaled from Go
+// durations such as "1h30m", ISO 8601 durations such as "PT1H30M", human
+// durations such as "90 minutes" or "1.5 hours", or a bare number of seconds.
+// See DurationFormat.ParseDuration for details. It is marshaled in the style
+// of DefaultDurationFormat.
+type RobustDuration time.Duration
+
+// String implements the flag.Value interface.
+func (rd RobustDuration) String() string {
+	return DefaultDurationFormat.FormatDuration(time.Duration(rd))
+}
+
+// Set implements the flag.Value interface.
+func (rd *RobustDuration) Set(s string) error {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	d, err := DefaultDurationFormat.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("marshaler.RobustDuration.Set: cannot parse \"%s\"", s)
+	}
+	*rd = RobustDuration(d)
+	return nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (rd RobustDuration) MarshalText() ([]byte, error) {
+	return []byte(rd.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (rd *RobustDuration) UnmarshalText(text []byte) error {
+	return rd.Set(string(text))
+}
+
+// MarshalJSON implements the json.Marshaler interface. The value is written
+// as a JSON string.
+func (rd RobustDuration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(rd.String())
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. The value may be
+// a JSON string, or a JSON number in units of DefaultDurationFormat.Unit.
+func (rd *RobustDuration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		b = []byte(s)
+	}
+	return rd.UnmarshalText(b)
+}
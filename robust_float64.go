@@ -17,7 +17,6 @@ package marshaler
 import (
 	"encoding/json"
 	"fmt"
-	"strconv"
 	"strings"
 )
 
@@ -26,7 +25,7 @@ type RobustFloat64 float64
 
 // String implements the flag.Value interface.
 func (rf RobustFloat64) String() string {
-	return fmt.Sprintf("%f", rf)
+	return DefaultNumberFormat.FormatFloat(float64(rf), 64)
 }
 
 // Set implements the flag.Value interface.
@@ -35,7 +34,10 @@ func (rf *RobustFloat64) Set(s string) error {
 	if s == "" {
 		return nil
 	}
-	f, err := strconv.ParseFloat(s, 64)
+	f, err := DefaultNumberFormat.ParseFloat(s, 64)
+	if unwrapNumError(err) == ErrMissing {
+		return nil
+	}
 	if err != nil {
 		return fmt.Errorf("marshaler.RobustFloat64.Set: cannot parse \"%s\"", s)
 	}
@@ -43,11 +45,24 @@ func (rf *RobustFloat64) Set(s string) error {
 	return nil
 }
 
+// MarshalText implements the encoding.TextMarshaler interface.
+func (rf RobustFloat64) MarshalText() ([]byte, error) {
+	return []byte(rf.String()), nil
+}
+
 // UnmarshalText implements the encoding.TextUnmarshaler interface.
 func (rf *RobustFloat64) UnmarshalText(text []byte) error {
 	return rf.Set(string(text))
 }
 
+// MarshalJSON implements the json.Marshaler interface. The value is written
+// as a JSON number, or as a JSON string if DefaultNumberFormat.JSONString is
+// set. NaN and infinities are written according to
+// DefaultNumberFormat.NonFinite.
+func (rf RobustFloat64) MarshalJSON() ([]byte, error) {
+	return DefaultNumberFormat.marshalFloat("RobustFloat64", float64(rf), 64)
+}
+
 // UnmarshalJSON implements the json.Unmarshaler interface.
 func (rf *RobustFloat64) UnmarshalJSON(b []byte) error {
 	var s string
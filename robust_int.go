@@ -16,12 +16,12 @@ package marshaler
 
 import (
 	"encoding/json"
-	"fmt"
 	"strconv"
 	"strings"
 )
 
-// A RobustInt is an int that can be unmarshaled from a string.
+// A RobustInt is an int that can be unmarshaled from a string or rounded
+// from a float.
 type RobustInt int
 
 // String implements the flag.Value interface.
@@ -35,19 +35,31 @@ func (ri *RobustInt) Set(s string) error {
 	if s == "" {
 		return nil
 	}
-	i, err := strconv.ParseInt(s, 10, 64)
+	i, err := DefaultNumberFormat.ParseInt(s, strconv.IntSize)
 	if err != nil {
-		return fmt.Errorf("marshaler.RobustInt.Set: cannot parse \"%s\"", s)
+		return parseError("RobustInt", s, err)
 	}
 	*ri = RobustInt(i)
 	return nil
 }
 
+// MarshalText implements the encoding.TextMarshaler interface.
+func (ri RobustInt) MarshalText() ([]byte, error) {
+	return []byte(ri.String()), nil
+}
+
 // UnmarshalText implements the encoding.TextUnmarshaler interface.
 func (ri *RobustInt) UnmarshalText(text []byte) error {
 	return ri.Set(string(text))
 }
 
+// MarshalJSON implements the json.Marshaler interface. The value is written
+// as a JSON number, or as a JSON string if DefaultNumberFormat.JSONString is
+// set.
+func (ri RobustInt) MarshalJSON() ([]byte, error) {
+	return DefaultNumberFormat.marshalNumber(ri.String()), nil
+}
+
 // UnmarshalJSON implements the json.Unmarshaler interface.
 func (ri *RobustInt) UnmarshalJSON(b []byte) error {
 	var s string
@@ -16,8 +16,6 @@ package marshaler
 
 import (
 	"encoding/json"
-	"fmt"
-	"math"
 	"strconv"
 	"strings"
 )
@@ -37,23 +35,31 @@ func (ri *RobustInt32) Set(s string) error {
 	if s == "" {
 		return nil
 	}
-	i, err := strconv.ParseInt(s, 10, 32)
+	i, err := DefaultNumberFormat.ParseInt(s, 32)
 	if err != nil {
-		f, err := strconv.ParseFloat(s, 64)
-		if err != nil {
-			return fmt.Errorf("marshaler.RobustInt32.Set: cannot parse \"%s\"", s)
-		}
-		i = int64(math.Round(f))
+		return parseError("RobustInt32", s, err)
 	}
 	*ri = RobustInt32(i)
 	return nil
 }
 
+// MarshalText implements the encoding.TextMarshaler interface.
+func (ri RobustInt32) MarshalText() ([]byte, error) {
+	return []byte(ri.String()), nil
+}
+
 // UnmarshalText implements the encoding.TextUnmarshaler interface.
 func (ri *RobustInt32) UnmarshalText(text []byte) error {
 	return ri.Set(string(text))
 }
 
+// MarshalJSON implements the json.Marshaler interface. The value is written
+// as a JSON number, or as a JSON string if DefaultNumberFormat.JSONString is
+// set.
+func (ri RobustInt32) MarshalJSON() ([]byte, error) {
+	return DefaultNumberFormat.marshalNumber(ri.String()), nil
+}
+
 // UnmarshalJSON implements the json.Unmarshaler interface.
 func (ri *RobustInt32) UnmarshalJSON(b []byte) error {
 	var s string
@@ -16,12 +16,12 @@ package marshaler
 
 import (
 	"encoding/json"
-	"fmt"
 	"strconv"
 	"strings"
 )
 
-// A RobustUint is a uint that can be unmarshaled from a string.
+// A RobustUint is a uint that can be unmarshaled from a string or rounded
+// from a float.
 type RobustUint uint
 
 // String implements the flag.Value interface.
@@ -35,19 +35,31 @@ func (ri *RobustUint) Set(s string) error {
 	if s == "" {
 		return nil
 	}
-	i, err := strconv.ParseUint(s, 10, 64)
+	i, err := DefaultNumberFormat.ParseUint(s, strconv.IntSize)
 	if err != nil {
-		return fmt.Errorf("marshaler.RobustUint.Set: cannot parse \"%s\"", s)
+		return parseError("RobustUint", s, err)
 	}
 	*ri = RobustUint(i)
 	return nil
 }
 
+// MarshalText implements the encoding.TextMarshaler interface.
+func (ri RobustUint) MarshalText() ([]byte, error) {
+	return []byte(ri.String()), nil
+}
+
 // UnmarshalText implements the encoding.TextUnmarshaler interface.
 func (ri *RobustUint) UnmarshalText(text []byte) error {
 	return ri.Set(string(text))
 }
 
+// MarshalJSON implements the json.Marshaler interface. The value is written
+// as a JSON number, or as a JSON string if DefaultNumberFormat.JSONString is
+// set.
+func (ri RobustUint) MarshalJSON() ([]byte, error) {
+	return DefaultNumberFormat.marshalNumber(ri.String()), nil
+}
+
 // UnmarshalJSON implements the json.Unmarshaler interface.
 func (ri *RobustUint) UnmarshalJSON(b []byte) error {
 	var s string
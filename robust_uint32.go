@@ -16,12 +16,12 @@ package marshaler
 
 import (
 	"encoding/json"
-	"fmt"
 	"strconv"
 	"strings"
 )
 
-// A RobustUint32 is a uint32 that can be unmarshaled from a string.
+// A RobustUint32 is a uint32 that can be unmarshaled from a string or rounded
+// from a float.
 type RobustUint32 uint32
 
 // String implements the flag.Value interface.
@@ -35,19 +35,31 @@ func (ru *RobustUint32) Set(s string) error {
 	if s == "" {
 		return nil
 	}
-	u, err := strconv.ParseUint(s, 10, 32)
+	u, err := DefaultNumberFormat.ParseUint(s, 32)
 	if err != nil {
-		return fmt.Errorf("marshaler.RobustUint32.Set: cannot parse \"%s\"", s)
+		return parseError("RobustUint32", s, err)
 	}
 	*ru = RobustUint32(u)
 	return nil
 }
 
-// UnmarshalText implements the encoding.TextMarshaler interface.
+// MarshalText implements the encoding.TextMarshaler interface.
+func (ru RobustUint32) MarshalText() ([]byte, error) {
+	return []byte(ru.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
 func (ru *RobustUint32) UnmarshalText(text []byte) error {
 	return ru.Set(string(text))
 }
 
+// MarshalJSON implements the json.Marshaler interface. The value is written
+// as a JSON number, or as a JSON string if DefaultNumberFormat.JSONString is
+// set.
+func (ru RobustUint32) MarshalJSON() ([]byte, error) {
+	return DefaultNumberFormat.marshalNumber(ru.String()), nil
+}
+
 // UnmarshalJSON implements the json.Unmarshaler interface.
 func (ru *RobustUint32) UnmarshalJSON(b []byte) error {
 	var s string
@@ -0,0 +1,173 @@
+// Copyright 2019 Miles Barr <milesbarr2@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marshaler
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ErrFractional is reported when a number with a fractional part is parsed
+// as an integer with the RoundReject rounding mode.
+var ErrFractional = errors.New("value has a fractional part")
+
+// A RoundingMode selects how a number with a fractional part is rounded.
+type RoundingMode int
+
+const (
+	// RoundHalfAway rounds to the nearest value, and halfway values away
+	// from zero, like math.Round.
+	RoundHalfAway RoundingMode = iota
+
+	// RoundHalfEven rounds to the nearest value, and halfway values to the
+	// nearest even value, like math.RoundToEven.
+	RoundHalfEven
+
+	// RoundTruncate rounds toward zero, like math.Trunc.
+	RoundTruncate
+
+	// RoundFloor rounds toward negative infinity, like math.Floor.
+	RoundFloor
+
+	// RoundCeil rounds toward positive infinity, like math.Ceil.
+	RoundCeil
+
+	// RoundReject does not round and reports ErrFractional instead.
+	RoundReject
+)
+
+// roundUp reports whether a value with the given sign, last retained digit
+// and discarded fraction should be rounded away from zero. cmp is -1, 0 or 1
+// as the fraction is less than, equal to or greater than one half, and zero
+// reports whether the fraction is zero.
+func (m RoundingMode) roundUp(neg bool, last byte, cmp int, zero bool) (bool, error) {
+	if zero {
+		return false, nil
+	}
+	switch m {
+	case RoundHalfEven:
+		return cmp > 0 || cmp == 0 && (last-'0')%2 == 1, nil
+	case RoundTruncate:
+		return false, nil
+	case RoundFloor:
+		return neg, nil
+	case RoundCeil:
+		return !neg, nil
+	case RoundReject:
+		return false, ErrFractional
+	}
+	return cmp >= 0, nil
+}
+
+// roundFloat rounds f to an integer according to m.
+func (m RoundingMode) roundFloat(f float64) (float64, error) {
+	switch m {
+	case RoundHalfEven:
+		return math.RoundToEven(f), nil
+	case RoundTruncate:
+		return math.Trunc(f), nil
+	case RoundFloor:
+		return math.Floor(f), nil
+	case RoundCeil:
+		return math.Ceil(f), nil
+	case RoundReject:
+		if f != math.Trunc(f) {
+			return 0, ErrFractional
+		}
+		return f, nil
+	}
+	return math.Round(f), nil
+}
+
+// roundInteger rewrites the number n, which may have a fractional part and
+// an exponent, as an integer rounded according to m. Decimal numbers are
+// rounded exactly; other syntaxes accepted by strconv.ParseFloat, such as
+// hexadecimal floats, are rounded in floating point.
+func (m RoundingMode) roundInteger(n string) (string, error) {
+	if !strings.ContainsAny(n, ".eEpP") {
+		return n, nil
+	}
+	neg, digits, point, ok := splitDecimal(n)
+	if !ok {
+		f, err := strconv.ParseFloat(n, 64)
+		if err != nil || math.IsNaN(f) {
+			return "", strconv.ErrSyntax
+		}
+		if math.IsInf(f, 0) {
+			return "", strconv.ErrRange
+		}
+		if f, err = m.roundFloat(f); err != nil {
+			return "", err
+		}
+		return strconv.FormatFloat(f, 'f', 0, 64), nil
+	}
+	if point > 1000 {
+		return "", strconv.ErrRange
+	}
+
+	var intPart, frac string
+	switch {
+	case point <= 0:
+		// The first digit of the fraction is a zero unless point is zero.
+		intPart, frac = "0", digits
+		if point < 0 {
+			frac = "0" + digits
+		}
+	case point >= len(digits):
+		intPart = digits + strings.Repeat("0", point-len(digits))
+	default:
+		intPart, frac = digits[:point], digits[point:]
+	}
+
+	zero := strings.Trim(frac, "0") == ""
+	cmp := -1
+	switch {
+	case frac == "" || frac[0] < '5':
+	case frac[0] > '5' || strings.Trim(frac[1:], "0") != "":
+		cmp = 1
+	default:
+		cmp = 0
+	}
+	up, err := m.roundUp(neg, intPart[len(intPart)-1], cmp, zero)
+	if err != nil {
+		return "", err
+	}
+	if up {
+		intPart = increment(intPart)
+	}
+	intPart = strings.TrimLeft(intPart, "0")
+	if intPart == "" {
+		return "0", nil
+	}
+	if neg {
+		return "-" + intPart, nil
+	}
+	return intPart, nil
+}
+
+// increment adds one to the decimal digit string s.
+func increment(s string) string {
+	b := []byte(s)
+	for i := len(b) - 1; i >= 0; i-- {
+		if b[i] < '9' {
+			b[i]++
+			return string(b)
+		}
+		b[i] = '0'
+	}
+	return "1" + string(b)
+}
@@ -0,0 +1,92 @@
+// Copyright 2019 Miles Barr <milesbarr2@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marshaler_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/jadefox10200/marshaler"
+)
+
+// numErr returns the error wrapped by a *strconv.NumError.
+func numErr(err error) error {
+	if ne, ok := err.(*strconv.NumError); ok {
+		return ne.Err
+	}
+	return err
+}
+
+func TestRoundingModes(t *testing.T) {
+	inputs := []string{"0.5", "-0.5", "1.5", "-1.5", "2.5", "-2.5", "-0.4", "2.51", "25e-1", "0x1.4p1"}
+	tests := []struct {
+		mode marshaler.RoundingMode
+		want []int64
+	}{
+		{marshaler.RoundHalfAway, []int64{1, -1, 2, -2, 3, -3, 0, 3, 3, 3}},
+		{marshaler.RoundHalfEven, []int64{0, 0, 2, -2, 2, -2, 0, 3, 2, 2}},
+		{marshaler.RoundTruncate, []int64{0, 0, 1, -1, 2, -2, 0, 2, 2, 2}},
+		{marshaler.RoundFloor, []int64{0, -1, 1, -2, 2, -3, -1, 2, 2, 2}},
+		{marshaler.RoundCeil, []int64{1, 0, 2, -1, 3, -2, 0, 3, 3, 3}},
+	}
+	for _, tt := range tests {
+		nf := marshaler.NumberFormat{Rounding: tt.mode}
+		for i, in := range inputs {
+			got, err := nf.ParseInt(in, 64)
+			if err != nil || got != tt.want[i] {
+				t.Errorf("mode %d: ParseInt(%q) = %d, %v; want %d", tt.mode, in, got, err, tt.want[i])
+			}
+		}
+	}
+	reject := marshaler.NumberFormat{Rounding: marshaler.RoundReject}
+	for _, in := range inputs {
+		if _, err := reject.ParseInt(in, 64); numErr(err) != marshaler.ErrFractional {
+			t.Errorf("RoundReject: ParseInt(%q) error = %v, want ErrFractional", in, err)
+		}
+	}
+	for _, in := range []string{"2.0", "25e-1e", "-3", "1.5e1"} {
+		_, err := reject.ParseInt(in, 64)
+		if in == "25e-1e" {
+			if numErr(err) != strconv.ErrSyntax {
+				t.Errorf("RoundReject: ParseInt(%q) error = %v, want ErrSyntax", in, err)
+			}
+		} else if err != nil {
+			t.Errorf("RoundReject: ParseInt(%q): %v", in, err)
+		}
+	}
+}
+
+func TestRoundingRange(t *testing.T) {
+	var nf marshaler.NumberFormat
+	if _, err := nf.ParseInt("1e12", 32); numErr(err) != strconv.ErrRange {
+		t.Errorf("ParseInt(1e12, 32) error = %v, want ErrRange", err)
+	}
+	if n, err := nf.ParseInt("1e12", 64); err != nil || n != 1000000000000 {
+		t.Errorf("ParseInt(1e12, 64) = %d, %v", n, err)
+	}
+	if _, err := nf.ParseInt("1e1001", 64); numErr(err) != strconv.ErrRange {
+		t.Errorf("ParseInt(1e1001) error = %v, want ErrRange", err)
+	}
+	if _, err := nf.ParseUint("-1", 64); numErr(err) != strconv.ErrRange {
+		t.Errorf("ParseUint(-1) error = %v, want ErrRange", err)
+	}
+	if n, err := nf.ParseUint("-0.4", 64); err != nil || n != 0 {
+		t.Errorf("ParseUint(-0.4) = %d, %v; want 0", n, err)
+	}
+	var u marshaler.RobustUint
+	if err := u.Set("-1"); err == nil || err.Error() != `marshaler.RobustUint.Set: "-1" is out of range` {
+		t.Errorf("RobustUint.Set(-1) error = %v", err)
+	}
+}
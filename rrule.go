@@ -0,0 +1,744 @@
+// Copyright 2019 Miles Barr <milesbarr2@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marshaler
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A Frequency is the FREQ of a recurrence rule.
+type Frequency int
+
+// Frequencies from the coarsest to the finest.
+const (
+	FreqYearly Frequency = iota + 1
+	FreqMonthly
+	FreqWeekly
+	FreqDaily
+	FreqHourly
+	FreqMinutely
+	FreqSecondly
+)
+
+var frequencyNames = [...]string{"", "YEARLY", "MONTHLY", "WEEKLY", "DAILY", "HOURLY", "MINUTELY", "SECONDLY"}
+
+// String returns the name of f, such as "MONTHLY".
+func (f Frequency) String() string {
+	if f < FreqYearly || f > FreqSecondly {
+		return "Frequency(" + strconv.Itoa(int(f)) + ")"
+	}
+	return frequencyNames[f]
+}
+
+// weekdayNames are the iCalendar names of the days of the week.
+var weekdayNames = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// A WeekdayNum is an element of the BYDAY rule part, such as "FR" or "-1FR".
+// If N is not zero, it is the Nth Weekday of the month or year, counting
+// from the end if N is negative.
+type WeekdayNum struct {
+	N       int
+	Weekday time.Weekday
+}
+
+// String returns wn in iCalendar format.
+func (wn WeekdayNum) String() string {
+	if wn.N == 0 {
+		return weekdayNames[wn.Weekday]
+	}
+	return strconv.Itoa(wn.N) + weekdayNames[wn.Weekday]
+}
+
+// An RRule is an iCalendar recurrence rule, as defined by RFC 5545, such as
+// "FREQ=MONTHLY;BYDAY=-1FR". Rules are validated when they are parsed and
+// are marshaled with their rule parts in a canonical order.
+//
+// Occurrences are generated from a start time, the DTSTART of the rule,
+// which is only an occurrence itself if it matches the rule.
+type RRule struct {
+	freq     Frequency
+	interval int
+	count    int
+
+	// until is the UNTIL rule part, with its wall clock in UTC. untilForm
+	// is 'D' for a date, 'Z' for a UTC time and 'L' for a local time.
+	until     time.Time
+	untilForm byte
+
+	bySecond   []int
+	byMinute   []int
+	byHour     []int
+	byDay      []WeekdayNum
+	byMonthDay []int
+	byYearDay  []int
+	byWeekNo   []int
+	byMonth    []int
+	bySetPos   []int
+
+	// wkst is the WKST rule part, if set.
+	wkst    time.Weekday
+	hasWkst bool
+}
+
+// errRRule is reported for an invalid recurrence rule.
+var errRRule = errors.New("invalid recurrence rule")
+
+// ParseRRule parses s as an RRule. An "RRULE:" prefix is allowed, and rule
+// part names and values are not case sensitive.
+func ParseRRule(s string) (RRule, error) {
+	r, err := parseRRule(s)
+	if err != nil {
+		return RRule{}, fmt.Errorf("marshaler.ParseRRule: cannot parse \"%s\": %v", s, err)
+	}
+	return r, nil
+}
+
+func parseRRule(s string) (RRule, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	s = strings.TrimPrefix(s, "RRULE:")
+	r := RRule{interval: 1}
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(s, ";") {
+		if part == "" {
+			continue
+		}
+		i := strings.IndexByte(part, '=')
+		if i < 0 {
+			return RRule{}, errRRule
+		}
+		name, value := part[:i], part[i+1:]
+		if seen[name] {
+			return RRule{}, fmt.Errorf("duplicate %s", name)
+		}
+		seen[name] = true
+		var err error
+		switch name {
+		case "FREQ":
+			for f := FreqYearly; f <= FreqSecondly; f++ {
+				if value == frequencyNames[f] {
+					r.freq = f
+				}
+			}
+			if r.freq == 0 {
+				err = errRRule
+			}
+		case "INTERVAL":
+			r.interval, err = strconv.Atoi(value)
+			if err == nil && r.interval < 1 {
+				err = errRRule
+			}
+		case "COUNT":
+			r.count, err = strconv.Atoi(value)
+			if err == nil && r.count < 1 {
+				err = errRRule
+			}
+		case "UNTIL":
+			r.until, r.untilForm, err = parseUntil(value)
+		case "BYSECOND":
+			r.bySecond, err = parseIntList(value, 0, 60, false)
+		case "BYMINUTE":
+			r.byMinute, err = parseIntList(value, 0, 59, false)
+		case "BYHOUR":
+			r.byHour, err = parseIntList(value, 0, 23, false)
+		case "BYDAY":
+			r.byDay, err = parseWeekdayList(value)
+		case "BYMONTHDAY":
+			r.byMonthDay, err = parseIntList(value, 1, 31, true)
+		case "BYYEARDAY":
+			r.byYearDay, err = parseIntList(value, 1, 366, true)
+		case "BYWEEKNO":
+			r.byWeekNo, err = parseIntList(value, 1, 53, true)
+		case "BYMONTH":
+			r.byMonth, err = parseIntList(value, 1, 12, false)
+		case "BYSETPOS":
+			r.bySetPos, err = parseIntList(value, 1, 366, true)
+		case "WKST":
+			var wd WeekdayNum
+			if wd, err = parseWeekdayNum(value); err == nil && wd.N != 0 {
+				err = errRRule
+			}
+			r.wkst, r.hasWkst = wd.Weekday, true
+		default:
+			return RRule{}, fmt.Errorf("unknown rule part %s", name)
+		}
+		if err != nil {
+			return RRule{}, fmt.Errorf("invalid %s", name)
+		}
+	}
+	return r, r.validate()
+}
+
+// validate checks the combination of rule parts of r.
+func (r RRule) validate() error {
+	switch {
+	case r.freq == 0:
+		return errors.New("missing FREQ")
+	case r.count != 0 && r.untilForm != 0:
+		return errors.New("both COUNT and UNTIL")
+	case len(r.byWeekNo) > 0 && r.freq != FreqYearly:
+		return errors.New("BYWEEKNO without FREQ=YEARLY")
+	case len(r.byYearDay) > 0 && (r.freq == FreqMonthly || r.freq == FreqWeekly || r.freq == FreqDaily):
+		return fmt.Errorf("BYYEARDAY with FREQ=%s", r.freq)
+	case len(r.byMonthDay) > 0 && r.freq == FreqWeekly:
+		return errors.New("BYMONTHDAY with FREQ=WEEKLY")
+	case len(r.bySetPos) > 0 && len(r.bySecond)+len(r.byMinute)+len(r.byHour)+len(r.byDay)+
+		len(r.byMonthDay)+len(r.byYearDay)+len(r.byWeekNo)+len(r.byMonth) == 0:
+		return errors.New("BYSETPOS without another BYxxx rule part")
+	}
+	for _, wd := range r.byDay {
+		if wd.N != 0 && (r.freq != FreqMonthly && r.freq != FreqYearly || len(r.byWeekNo) > 0) {
+			return fmt.Errorf("BYDAY=%s with FREQ=%s", wd, r.freq)
+		}
+	}
+	return nil
+}
+
+// parseUntil parses the value of the UNTIL rule part.
+func parseUntil(s string) (time.Time, byte, error) {
+	form := byte('L')
+	layout := "20060102T150405"
+	switch {
+	case len(s) == 8:
+		form, layout = 'D', "20060102"
+	case strings.HasSuffix(s, "Z"):
+		form, s = 'Z', s[:len(s)-1]
+	}
+	t, err := time.Parse(layout, s)
+	return t, form, err
+}
+
+// parseIntList parses a comma-separated list of integers whose absolute
+// values are between min and max. Negative values are only accepted if neg
+// is set.
+func parseIntList(s string, min, max int, neg bool) ([]int, error) {
+	var list []int
+	for _, f := range strings.Split(s, ",") {
+		n, err := strconv.Atoi(f)
+		if err != nil {
+			return nil, err
+		}
+		abs := n
+		if n < 0 && neg {
+			abs = -n
+		}
+		if abs < min || abs > max {
+			return nil, errRRule
+		}
+		list = append(list, n)
+	}
+	return list, nil
+}
+
+// parseWeekdayList parses the value of the BYDAY rule part.
+func parseWeekdayList(s string) ([]WeekdayNum, error) {
+	var list []WeekdayNum
+	for _, f := range strings.Split(s, ",") {
+		wd, err := parseWeekdayNum(f)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, wd)
+	}
+	return list, nil
+}
+
+// parseWeekdayNum parses a weekday with an optional ordinal, such as "-1FR".
+func parseWeekdayNum(s string) (WeekdayNum, error) {
+	if len(s) < 2 {
+		return WeekdayNum{}, errRRule
+	}
+	var wn WeekdayNum
+	if n := s[:len(s)-2]; n != "" {
+		var err error
+		if wn.N, err = strconv.Atoi(n); err != nil || wn.N == 0 || wn.N < -53 || wn.N > 53 {
+			return WeekdayNum{}, errRRule
+		}
+	}
+	for i, name := range weekdayNames {
+		if s[len(s)-2:] == name {
+			wn.Weekday = time.Weekday(i)
+			return wn, nil
+		}
+	}
+	return WeekdayNum{}, errRRule
+}
+
+// Freq returns the frequency of r.
+func (r RRule) Freq() Frequency {
+	return r.freq
+}
+
+// Interval returns the interval of r, which is 1 unless set.
+func (r RRule) Interval() int {
+	return r.interval
+}
+
+// Count returns the number of occurrences of r, or 0 if it is not limited.
+func (r RRule) Count() int {
+	return r.count
+}
+
+// String implements the flag.Value interface.
+func (r RRule) String() string {
+	if r.freq == 0 {
+		return ""
+	}
+	parts := []string{"FREQ=" + r.freq.String()}
+	if r.interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.interval))
+	}
+	if r.count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.count))
+	}
+	switch r.untilForm {
+	case 'D':
+		parts = append(parts, "UNTIL="+r.until.Format("20060102"))
+	case 'Z':
+		parts = append(parts, "UNTIL="+r.until.Format("20060102T150405Z"))
+	case 'L':
+		parts = append(parts, "UNTIL="+r.until.Format("20060102T150405"))
+	}
+	ints := func(name string, list []int) {
+		if len(list) > 0 {
+			s := make([]string, len(list))
+			for i, n := range list {
+				s[i] = strconv.Itoa(n)
+			}
+			parts = append(parts, name+"="+strings.Join(s, ","))
+		}
+	}
+	ints("BYSECOND", r.bySecond)
+	ints("BYMINUTE", r.byMinute)
+	ints("BYHOUR", r.byHour)
+	if len(r.byDay) > 0 {
+		s := make([]string, len(r.byDay))
+		for i, wd := range r.byDay {
+			s[i] = wd.String()
+		}
+		parts = append(parts, "BYDAY="+strings.Join(s, ","))
+	}
+	ints("BYMONTHDAY", r.byMonthDay)
+	ints("BYYEARDAY", r.byYearDay)
+	ints("BYWEEKNO", r.byWeekNo)
+	ints("BYMONTH", r.byMonth)
+	ints("BYSETPOS", r.bySetPos)
+	if r.hasWkst {
+		parts = append(parts, "WKST="+weekdayNames[r.wkst])
+	}
+	return strings.Join(parts, ";")
+}
+
+// Set implements the flag.Value interface.
+func (r *RRule) Set(s string) error {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	v, err := parseRRule(s)
+	if err != nil {
+		return fmt.Errorf("marshaler.RRule.Set: cannot parse \"%s\": %v", s, err)
+	}
+	*r = v
+	return nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (r RRule) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (r *RRule) UnmarshalText(text []byte) error {
+	return r.Set(string(text))
+}
+
+// Between returns the occurrences of r starting at dtstart that are not
+// before after and not after before, excluding exdates.
+func (r RRule) Between(dtstart, after, before time.Time, exdates ...time.Time) []time.Time {
+	var ts []time.Time
+	it := r.Iterator(dtstart, exdates...)
+	for {
+		t, ok := it.Next()
+		if !ok || t.After(before) {
+			return ts
+		}
+		if !t.Before(after) {
+			ts = append(ts, t)
+		}
+	}
+}
+
+// An RRuleIterator generates the occurrences of an RRule in order.
+type RRuleIterator struct {
+	r       RRule
+	start   time.Time
+	until   time.Time
+	exdates map[[2]int64]bool
+
+	// base is the wall clock of the first period in UTC.
+	base   time.Time
+	period int
+
+	buf  []time.Time
+	n    int
+	last time.Time
+	done bool
+}
+
+// maxGapYears is the number of years after which an RRuleIterator gives up
+// looking for the next occurrence. The Gregorian calendar repeats every 400
+// years.
+const maxGapYears = 401
+
+// Iterator returns an iterator over the occurrences of r starting at
+// dtstart, in the location of dtstart, excluding exdates. Rule parts that are
+// not set default to the corresponding fields of dtstart as described in RFC
+// 5545, so "FREQ=MONTHLY" recurs on the day of the month of dtstart.
+func (r RRule) Iterator(dtstart time.Time, exdates ...time.Time) *RRuleIterator {
+	it := &RRuleIterator{r: r.withDefaults(dtstart), start: dtstart, last: dtstart}
+	loc := dtstart.Location()
+	switch r.untilForm {
+	case 'D':
+		y, m, d := r.until.Date()
+		it.until = time.Date(y, m, d+1, 0, 0, 0, 0, loc).Add(-time.Nanosecond)
+	case 'Z':
+		it.until = r.until
+	case 'L':
+		y, m, d := r.until.Date()
+		h, min, s := r.until.Clock()
+		it.until = time.Date(y, m, d, h, min, s, 0, loc)
+	}
+	it.exdates = make(map[[2]int64]bool)
+	for _, t := range exdates {
+		it.exdates[[2]int64{t.Unix(), int64(t.Nanosecond())}] = true
+	}
+	y, m, d := dtstart.Date()
+	h, min, s := dtstart.Clock()
+	switch r.freq {
+	case FreqHourly:
+		it.base = time.Date(y, m, d, h, 0, 0, 0, time.UTC)
+	case FreqMinutely:
+		it.base = time.Date(y, m, d, h, min, 0, 0, time.UTC)
+	case FreqSecondly:
+		it.base = time.Date(y, m, d, h, min, s, 0, time.UTC)
+	case FreqWeekly:
+		it.base = time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+		it.base = it.base.AddDate(0, 0, -((int(it.base.Weekday()) - int(r.weekStart()) + 7) % 7))
+	default:
+		it.base = time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+	if r.freq == 0 {
+		it.done = true
+	}
+	return it
+}
+
+// IteratorFlexibleTime is like Iterator for a DTSTART and EXDATEs
+// unmarshaled as FlexibleTimes.
+func (r RRule) IteratorFlexibleTime(dtstart FlexibleTime, exdates ...FlexibleTime) *RRuleIterator {
+	times := make([]time.Time, len(exdates))
+	for i, t := range exdates {
+		times[i] = time.Time(t)
+	}
+	return r.Iterator(time.Time(dtstart), times...)
+}
+
+// IteratorDateTime is like Iterator for a DTSTART and EXDATEs unmarshaled
+// as DateTimes.
+func (r RRule) IteratorDateTime(dtstart DateTime, exdates ...DateTime) *RRuleIterator {
+	times := make([]time.Time, len(exdates))
+	for i, t := range exdates {
+		times[i] = time.Time(t)
+	}
+	return r.Iterator(time.Time(dtstart), times...)
+}
+
+// weekStart returns the first day of the week of r.
+func (r RRule) weekStart() time.Weekday {
+	if r.hasWkst {
+		return r.wkst
+	}
+	return time.Monday
+}
+
+// withDefaults returns r with the rule parts that RFC 5545 derives from
+// dtstart filled in, and its lists sorted.
+func (r RRule) withDefaults(dtstart time.Time) RRule {
+	_, m, d := dtstart.Date()
+	if len(r.byWeekNo)+len(r.byYearDay)+len(r.byMonthDay)+len(r.byDay) == 0 {
+		switch r.freq {
+		case FreqYearly:
+			if len(r.byMonth) == 0 {
+				r.byMonth = []int{int(m)}
+			}
+			r.byMonthDay = []int{d}
+		case FreqMonthly:
+			r.byMonthDay = []int{d}
+		case FreqWeekly:
+			r.byDay = []WeekdayNum{{Weekday: dtstart.Weekday()}}
+		}
+	}
+	h, min, s := dtstart.Clock()
+	r.byHour = timeValues(r.byHour, h, r.freq < FreqHourly)
+	r.byMinute = timeValues(r.byMinute, min, r.freq < FreqMinutely)
+	r.bySecond = timeValues(r.bySecond, s, r.freq < FreqSecondly)
+	return r
+}
+
+// timeValues returns the sorted values of a BYHOUR, BYMINUTE or BYSECOND
+// rule part, defaulting to the value of DTSTART if the frequency is coarser
+// than the unit.
+func timeValues(list []int, def int, coarser bool) []int {
+	if len(list) == 0 {
+		if coarser {
+			return []int{def}
+		}
+		return nil
+	}
+	list = append([]int(nil), list...)
+	sort.Ints(list)
+	return list
+}
+
+// Next returns the next occurrence, or false if there are no more.
+func (it *RRuleIterator) Next() (time.Time, bool) {
+	for !it.done {
+		for len(it.buf) > 0 {
+			t := it.buf[0]
+			it.buf = it.buf[1:]
+			if it.r.untilForm != 0 && t.After(it.until) || it.r.count > 0 && it.n >= it.r.count {
+				it.done = true
+				return time.Time{}, false
+			}
+			it.n++
+			it.last = t
+			if !it.exdates[[2]int64{t.Unix(), int64(t.Nanosecond())}] {
+				return t, true
+			}
+		}
+		it.buf = it.next()
+	}
+	return time.Time{}, false
+}
+
+// next returns the occurrences of the next period that are not before the
+// start, and ends the iteration if there are none for too long.
+func (it *RRuleIterator) next() []time.Time {
+	for {
+		days, start := it.periodDays()
+		if start.Year() > 9999 || it.r.untilForm != 0 && start.After(it.until.UTC().AddDate(0, 0, 1)) ||
+			start.After(it.last.UTC().AddDate(maxGapYears, 0, 0)) {
+			it.done = true
+			return nil
+		}
+		var ts []time.Time
+		for _, day := range days {
+			ts = it.appendTimes(ts, day, start)
+		}
+		ts = it.r.setPos(ts)
+		for len(ts) > 0 && ts[0].Before(it.start) {
+			ts = ts[1:]
+		}
+		if len(ts) > 0 {
+			return ts
+		}
+	}
+}
+
+// periodDays returns the days of the current period that match the rule and
+// the wall clock at which the period starts, and advances to the next
+// period. For frequencies finer than daily, periods on days that do not match
+// are skipped.
+func (it *RRuleIterator) periodDays() ([]time.Time, time.Time) {
+	r := it.r
+	k := it.period * r.interval
+	it.period++
+	switch r.freq {
+	case FreqYearly:
+		y := it.base.Year() + k
+		first, end := time.Date(y, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(y+1, 1, 1, 0, 0, 0, 0, time.UTC)
+		if len(r.byWeekNo) > 0 {
+			first, end = weekOne(y, r.weekStart()), weekOne(y+1, r.weekStart())
+		}
+		return r.matchDays(first, end), first
+	case FreqMonthly:
+		first := time.Date(it.base.Year(), it.base.Month()+time.Month(k), 1, 0, 0, 0, 0, time.UTC)
+		return r.matchDays(first, first.AddDate(0, 1, 0)), first
+	case FreqWeekly:
+		first := it.base.AddDate(0, 0, 7*k)
+		return r.matchDays(first, first.AddDate(0, 0, 7)), first
+	case FreqDaily:
+		first := it.base.AddDate(0, 0, k)
+		return r.matchDays(first, first.AddDate(0, 0, 1)), first
+	}
+	// Periods are counted in seconds, since a time.Duration only spans
+	// about 292 years.
+	step := map[Frequency]int64{FreqHourly: 3600, FreqMinutely: 60, FreqSecondly: 1}[r.freq]
+	start := time.Unix(it.base.Unix()+int64(k)*step, 0).UTC()
+	y, m, d := start.Date()
+	day := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	if days := r.matchDays(day, day.AddDate(0, 0, 1)); len(days) > 0 {
+		return days, start
+	}
+	// Skip to the first period of the next day.
+	step *= int64(r.interval)
+	gap := day.AddDate(0, 0, 1).Unix() - it.base.Unix()
+	it.period = int((gap + step - 1) / step)
+	return nil, start
+}
+
+// matchDays returns the days from first up to end that match the day rule
+// parts of r.
+func (r RRule) matchDays(first, end time.Time) []time.Time {
+	var days []time.Time
+	for d := first; d.Before(end); d = d.AddDate(0, 0, 1) {
+		if r.matchDay(d) {
+			days = append(days, d)
+		}
+	}
+	return days
+}
+
+// matchDay reports whether day matches the BYMONTH, BYWEEKNO, BYYEARDAY,
+// BYMONTHDAY and BYDAY rule parts of r.
+func (r RRule) matchDay(day time.Time) bool {
+	y, m, d := day.Date()
+	if len(r.byMonth) > 0 && !containsInt(r.byMonth, int(m), 0) {
+		return false
+	}
+	if len(r.byWeekNo) > 0 {
+		n, weeks := weekNo(day, r.weekStart())
+		if !containsInt(r.byWeekNo, n, weeks) {
+			return false
+		}
+	}
+	daysInYear := time.Date(y, 12, 31, 0, 0, 0, 0, time.UTC).YearDay()
+	if len(r.byYearDay) > 0 && !containsInt(r.byYearDay, day.YearDay(), daysInYear) {
+		return false
+	}
+	daysInMonth := time.Date(y, m+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	if len(r.byMonthDay) > 0 && !containsInt(r.byMonthDay, d, daysInMonth) {
+		return false
+	}
+	if len(r.byDay) == 0 {
+		return true
+	}
+	// Ordinals count within the month for monthly rules and yearly rules
+	// limited to months, and within the year otherwise.
+	pos, size := day.YearDay(), daysInYear
+	if r.freq == FreqMonthly || len(r.byMonth) > 0 {
+		pos, size = d, daysInMonth
+	}
+	for _, wd := range r.byDay {
+		if wd.Weekday != day.Weekday() {
+			continue
+		}
+		if wd.N == 0 || wd.N == (pos-1)/7+1 || wd.N == -((size-pos)/7+1) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsInt reports whether list contains n, where negative elements of
+// list count back from size, so -1 matches size.
+func containsInt(list []int, n, size int) bool {
+	for _, v := range list {
+		if v == n || v < 0 && size+v+1 == n {
+			return true
+		}
+	}
+	return false
+}
+
+// weekOne returns the first day of week 1 of year, which is the first week
+// starting on wkst with at least four days in the year.
+func weekOne(year int, wkst time.Weekday) time.Time {
+	jan1 := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+	offset := (int(jan1.Weekday()) - int(wkst) + 7) % 7
+	if offset <= 3 {
+		return jan1.AddDate(0, 0, -offset)
+	}
+	return jan1.AddDate(0, 0, 7-offset)
+}
+
+// weekNo returns the week number of day and the number of weeks in its
+// week-numbering year.
+func weekNo(day time.Time, wkst time.Weekday) (int, int) {
+	y := day.Year()
+	if day.Before(weekOne(y, wkst)) {
+		y--
+	} else if !day.Before(weekOne(y+1, wkst)) {
+		y++
+	}
+	first, next := weekOne(y, wkst), weekOne(y+1, wkst)
+	return int(day.Sub(first)/(7*24*time.Hour)) + 1, int(next.Sub(first) / (7 * 24 * time.Hour))
+}
+
+// appendTimes appends the occurrences on day to ts. For frequencies finer
+// than daily, only the times within the period starting at start are used.
+func (it *RRuleIterator) appendTimes(ts []time.Time, day, start time.Time) []time.Time {
+	r := it.r
+	hours, minutes, seconds := r.byHour, r.byMinute, r.bySecond
+	fixed := func(list []int, v int) []int {
+		if len(list) == 0 || containsInt(list, v, 0) {
+			return []int{v}
+		}
+		return nil
+	}
+	if r.freq >= FreqHourly {
+		hours = fixed(hours, start.Hour())
+	}
+	if r.freq >= FreqMinutely {
+		minutes = fixed(minutes, start.Minute())
+	}
+	if r.freq >= FreqSecondly {
+		seconds = fixed(seconds, start.Second())
+	}
+	y, m, d := day.Date()
+	loc, nsec := it.start.Location(), it.start.Nanosecond()
+	for _, h := range hours {
+		for _, min := range minutes {
+			for _, s := range seconds {
+				t := time.Date(y, m, d, h, min, s, nsec, loc)
+				// Skip wall clock times that do not exist, such as
+				// those in a daylight saving time gap.
+				if t.Hour() == h && t.Minute() == min {
+					ts = append(ts, t)
+				}
+			}
+		}
+	}
+	return ts
+}
+
+// setPos applies the BYSETPOS rule part of r to the occurrences of a period.
+func (r RRule) setPos(ts []time.Time) []time.Time {
+	if len(r.bySetPos) == 0 {
+		return ts
+	}
+	var out []time.Time
+	for i, t := range ts {
+		if containsInt(r.bySetPos, i+1, len(ts)) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
@@ -0,0 +1,177 @@
+// Copyright 2019 Miles Barr <milesbarr2@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marshaler_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jadefox10200/marshaler"
+)
+
+// occurrences returns at most n occurrences of it, formatted with layout.
+func occurrences(it *marshaler.RRuleIterator, n int, layout string) string {
+	var out []string
+	for i := 0; i < n; i++ {
+		t, ok := it.Next()
+		if !ok {
+			break
+		}
+		out = append(out, t.Format(layout))
+	}
+	return strings.Join(out, " ")
+}
+
+// TestRRuleRFC5545 checks the examples in section 3.8.5.3 of RFC 5545,
+// which start at 9:00 AM on September 2, 1997 in New York.
+func TestRRuleRFC5545(t *testing.T) {
+	ny := mustLoadLocation(t, "America/New_York")
+	dtstart := time.Date(1997, 9, 2, 9, 0, 0, 0, ny)
+	tests := []struct {
+		rule, want string
+	}{
+		{"FREQ=DAILY;COUNT=3", "1997-09-02T09:00 1997-09-03T09:00 1997-09-04T09:00"},
+		{"FREQ=DAILY;UNTIL=19970905T000000Z", "1997-09-02T09:00 1997-09-03T09:00 1997-09-04T09:00"},
+		{"FREQ=DAILY;INTERVAL=10;COUNT=3", "1997-09-02T09:00 1997-09-12T09:00 1997-09-22T09:00"},
+		{"FREQ=WEEKLY;COUNT=4;WKST=SU;BYDAY=TU,TH", "1997-09-02T09:00 1997-09-04T09:00 1997-09-09T09:00 1997-09-11T09:00"},
+		{"FREQ=MONTHLY;COUNT=4;BYDAY=1FR", "1997-09-05T09:00 1997-10-03T09:00 1997-11-07T09:00 1997-12-05T09:00"},
+		// Negative ordinals count from the end of the month or year.
+		{"FREQ=MONTHLY;BYDAY=-1FR;COUNT=3", "1997-09-26T09:00 1997-10-31T09:00 1997-11-28T09:00"},
+		{"FREQ=MONTHLY;COUNT=3;BYDAY=-2MO", "1997-09-22T09:00 1997-10-20T09:00 1997-11-17T09:00"},
+		{"FREQ=MONTHLY;BYMONTHDAY=-3;COUNT=3", "1997-09-28T09:00 1997-10-29T09:00 1997-11-28T09:00"},
+		{"FREQ=MONTHLY;BYMONTHDAY=1,-1;COUNT=4", "1997-09-30T09:00 1997-10-01T09:00 1997-10-31T09:00 1997-11-01T09:00"},
+		{"FREQ=YEARLY;BYYEARDAY=-1;COUNT=2", "1997-12-31T09:00 1998-12-31T09:00"},
+		{"FREQ=YEARLY;INTERVAL=3;COUNT=4;BYYEARDAY=1,100,200", "2000-01-01T09:00 2000-04-09T09:00 2000-07-18T09:00 2003-01-01T09:00"},
+		{"FREQ=YEARLY;BYDAY=20MO;COUNT=3", "1998-05-18T09:00 1999-05-17T09:00 2000-05-15T09:00"},
+		// ISO 8601 week numbers.
+		{"FREQ=YEARLY;BYWEEKNO=20;BYDAY=MO;COUNT=3", "1998-05-11T09:00 1999-05-17T09:00 2000-05-15T09:00"},
+		{"FREQ=YEARLY;BYWEEKNO=1;BYDAY=MO;COUNT=3", "1997-12-29T09:00 1999-01-04T09:00 2000-01-03T09:00"},
+		{"FREQ=YEARLY;BYWEEKNO=-1;BYDAY=SU;COUNT=2", "1997-12-28T09:00 1999-01-03T09:00"},
+		{"FREQ=YEARLY;BYMONTH=3;BYDAY=TH;COUNT=3", "1998-03-05T09:00 1998-03-12T09:00 1998-03-19T09:00"},
+		{"FREQ=MONTHLY;BYDAY=FR;BYMONTHDAY=13;COUNT=3", "1998-02-13T09:00 1998-03-13T09:00 1998-11-13T09:00"},
+		{"FREQ=YEARLY;INTERVAL=4;BYMONTH=11;BYDAY=TU;BYMONTHDAY=2,3,4,5,6,7,8;COUNT=3", "1997-11-04T09:00 2001-11-06T09:00 2005-11-08T09:00"},
+		// BYSETPOS selects from the occurrences in each period.
+		{"FREQ=MONTHLY;COUNT=3;BYDAY=TU,WE,TH;BYSETPOS=3", "1997-09-04T09:00 1997-10-07T09:00 1997-11-06T09:00"},
+		{"FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-2;COUNT=3", "1997-09-29T09:00 1997-10-30T09:00 1997-11-27T09:00"},
+		{"FREQ=HOURLY;INTERVAL=3;UNTIL=19970902T170000Z", "1997-09-02T09:00 1997-09-02T12:00"},
+		{"FREQ=MINUTELY;INTERVAL=15;COUNT=3", "1997-09-02T09:00 1997-09-02T09:15 1997-09-02T09:30"},
+		{"FREQ=DAILY;BYHOUR=9,10,11,12,13,14,15,16;BYMINUTE=0,20,40;COUNT=4", "1997-09-02T09:00 1997-09-02T09:20 1997-09-02T09:40 1997-09-02T10:00"},
+		{"FREQ=MONTHLY;BYMONTHDAY=15,30;COUNT=5", "1997-09-15T09:00 1997-09-30T09:00 1997-10-15T09:00 1997-10-30T09:00 1997-11-15T09:00"},
+		// Invalid dates are ignored.
+		{"FREQ=MONTHLY;BYMONTHDAY=31;COUNT=4", "1997-10-31T09:00 1997-12-31T09:00 1998-01-31T09:00 1998-03-31T09:00"},
+		{"FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30", ""},
+	}
+	for _, tt := range tests {
+		r, err := marshaler.ParseRRule(tt.rule)
+		if err != nil {
+			t.Errorf("ParseRRule(%q): %v", tt.rule, err)
+			continue
+		}
+		if got := occurrences(r.Iterator(dtstart), 10, "2006-01-02T15:04"); got != tt.want {
+			t.Errorf("%s:\n got %s\nwant %s", tt.rule, got, tt.want)
+		}
+	}
+}
+
+func TestRRuleIterator(t *testing.T) {
+	ny := mustLoadLocation(t, "America/New_York")
+	tests := []struct {
+		name, rule string
+		dtstart    time.Time
+		exdates    []time.Time
+		want       string
+	}{
+		{
+			name:    "short months are skipped",
+			rule:    "FREQ=MONTHLY;COUNT=3",
+			dtstart: time.Date(2019, 1, 31, 0, 0, 0, 0, time.UTC),
+			want:    "2019-01-31T00:00Z 2019-03-31T00:00Z 2019-05-31T00:00Z",
+		},
+		{
+			name:    "leap days",
+			rule:    "FREQ=YEARLY;COUNT=2",
+			dtstart: time.Date(2020, 2, 29, 0, 0, 0, 0, time.UTC),
+			want:    "2020-02-29T00:00Z 2024-02-29T00:00Z",
+		},
+		{
+			name:    "excluded dates count",
+			rule:    "FREQ=DAILY;COUNT=4",
+			dtstart: time.Date(1997, 9, 2, 9, 0, 0, 0, ny),
+			exdates: []time.Time{time.Date(1997, 9, 3, 9, 0, 0, 0, ny), time.Date(1997, 9, 4, 13, 0, 0, 0, time.UTC)},
+			want:    "1997-09-02T09:00-04:00 1997-09-05T09:00-04:00",
+		},
+		{
+			name:    "excluded dates must match exactly",
+			rule:    "FREQ=DAILY;COUNT=2",
+			dtstart: time.Date(1997, 9, 2, 9, 0, 0, 0, ny),
+			exdates: []time.Time{time.Date(1997, 9, 3, 0, 0, 0, 0, ny)},
+			want:    "1997-09-02T09:00-04:00 1997-09-03T09:00-04:00",
+		},
+		{
+			name:    "nonexistent local times are skipped",
+			rule:    "FREQ=DAILY;COUNT=3",
+			dtstart: time.Date(2021, 3, 13, 2, 30, 0, 0, ny),
+			want:    "2021-03-13T02:30-05:00 2021-03-15T02:30-04:00 2021-03-16T02:30-04:00",
+		},
+		{
+			name:    "local time is kept across transitions",
+			rule:    "FREQ=WEEKLY;COUNT=2",
+			dtstart: time.Date(2021, 11, 1, 9, 0, 0, 0, ny),
+			want:    "2021-11-01T09:00-04:00 2021-11-08T09:00-05:00",
+		},
+		{
+			name:    "hourly counts elapsed hours across a gap",
+			rule:    "FREQ=HOURLY;COUNT=3",
+			dtstart: time.Date(2021, 3, 14, 1, 0, 0, 0, ny),
+			want:    "2021-03-14T01:00-05:00 2021-03-14T03:00-04:00 2021-03-14T04:00-04:00",
+		},
+	}
+	for _, tt := range tests {
+		r, err := marshaler.ParseRRule(tt.rule)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got := occurrences(r.Iterator(tt.dtstart, tt.exdates...), 10, "2006-01-02T15:04Z07:00"); got != tt.want {
+			t.Errorf("%s:\n got %s\nwant %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRRuleIteratorFlexibleTime(t *testing.T) {
+	r, err := marshaler.ParseRRule("FREQ=WEEKLY;COUNT=3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dtstart, exdate marshaler.FlexibleTime
+	if err := dtstart.Set("2019-07-01T09:00:00Z"); err != nil {
+		t.Fatal(err)
+	}
+	if err := exdate.Set("2019-07-08T09:00:00Z"); err != nil {
+		t.Fatal(err)
+	}
+	want := "2019-07-01T09:00Z 2019-07-15T09:00Z"
+	if got := occurrences(r.IteratorFlexibleTime(dtstart, exdate), 10, "2006-01-02T15:04Z07:00"); got != want {
+		t.Errorf("IteratorFlexibleTime: got %s, want %s", got, want)
+	}
+	var dt marshaler.DateTime
+	if err := dt.Set("2019-07-01 09:00:00"); err != nil {
+		t.Fatal(err)
+	}
+	want = "2019-07-01 2019-07-08 2019-07-15"
+	if got := occurrences(r.IteratorDateTime(dt), 10, "2006-01-02"); got != want {
+		t.Errorf("IteratorDateTime: got %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2019 Miles Barr <milesbarr2@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marshaler
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// ErrMissing is reported when a missing-value sentinel, such as "N/A", is
+// parsed with the SentinelMissing policy. The Set methods of the Robust
+// float types treat it like an empty string and leave the value unchanged.
+var ErrMissing = errors.New("missing value")
+
+// errNonFinite is reported when a non-finite value is parsed with the
+// SentinelMissing or SentinelError policy.
+var errNonFinite = errors.New("non-finite value")
+
+// DefaultSentinels are the missing-value sentinels recognized when
+// NumberFormat.Sentinels is nil. They are matched regardless of case.
+var DefaultSentinels = []string{"NaN", "N/A", "NA", "#N/A", "-", "--", "null", "none", "nil"}
+
+// A SentinelPolicy selects how the Robust float types handle missing-value
+// sentinels, such as "N/A" or "--", and non-finite values.
+type SentinelPolicy int
+
+const (
+	// SentinelNone recognizes no sentinels. "NaN" and "Inf" are parsed as
+	// the strconv package parses them, so with NonFiniteError a parsed value
+	// may fail to marshal.
+	SentinelNone SentinelPolicy = iota
+
+	// SentinelMissing treats sentinels as missing values, which leave the
+	// value being unmarshaled unchanged, and rejects NaN and infinities
+	// that are not sentinels.
+	SentinelMissing
+
+	// SentinelNaN parses sentinels as NaN.
+	SentinelNaN
+
+	// SentinelError rejects sentinels, NaN and infinities.
+	SentinelError
+)
+
+// A NonFinitePolicy selects how MarshalJSON writes NaN and infinities, which
+// have no JSON number representation.
+type NonFinitePolicy int
+
+const (
+	// NonFiniteError reports an error, as encoding/json does for float64.
+	NonFiniteError NonFinitePolicy = iota
+
+	// NonFiniteNull writes null.
+	NonFiniteNull
+
+	// NonFiniteString writes the strings "NaN", "+Inf" and "-Inf", which
+	// are parsed back under the SentinelNone and SentinelNaN policies.
+	NonFiniteString
+)
+
+// sentinel reports whether the trimmed string s is a missing-value sentinel
+// in nf.
+func (nf NumberFormat) sentinel(s string) bool {
+	sentinels := nf.Sentinels
+	if sentinels == nil {
+		sentinels = DefaultSentinels
+	}
+	for _, t := range sentinels {
+		if strings.EqualFold(s, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkFinite applies nf.SentinelPolicy to a parsed float.
+func (nf NumberFormat) checkFinite(f float64) error {
+	switch nf.SentinelPolicy {
+	case SentinelNone, SentinelNaN:
+		return nil
+	}
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return errNonFinite
+	}
+	return nil
+}
+
+// marshalFloat returns the JSON encoding of f, which is a float of the given
+// bit size. NaN and infinities are written according to nf.NonFinite.
+func (nf NumberFormat) marshalFloat(typ string, f float64, bitSize int) ([]byte, error) {
+	if !math.IsNaN(f) && !math.IsInf(f, 0) {
+		return nf.marshalNumber(nf.FormatFloat(f, bitSize)), nil
+	}
+	switch nf.NonFinite {
+	case NonFiniteNull:
+		return []byte("null"), nil
+	case NonFiniteString:
+		switch {
+		case math.IsNaN(f):
+			return []byte(`"NaN"`), nil
+		case f > 0:
+			return []byte(`"+Inf"`), nil
+		}
+		return []byte(`"-Inf"`), nil
+	}
+	return nil, fmt.Errorf("marshaler.%s.MarshalJSON: unsupported value %v", typ, f)
+}
@@ -0,0 +1,95 @@
+// Copyright 2019 Miles Barr <milesbarr2@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marshaler_test
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/jadefox10200/marshaler"
+)
+
+func TestSentinelPolicy(t *testing.T) {
+	tests := []struct {
+		policy marshaler.SentinelPolicy
+		in     string
+		want   float64
+		ok     bool
+		err    error
+	}{
+		{marshaler.SentinelNone, "N/A", 0, false, strconv.ErrSyntax},
+		{marshaler.SentinelNone, "inf", math.Inf(1), true, nil},
+		{marshaler.SentinelMissing, "N/A", 0, false, marshaler.ErrMissing},
+		{marshaler.SentinelMissing, "nan", 0, false, marshaler.ErrMissing},
+		{marshaler.SentinelMissing, "inf", 0, false, nil},
+		{marshaler.SentinelMissing, "-Infinity", 0, false, nil},
+		{marshaler.SentinelMissing, "1.5", 1.5, true, nil},
+		{marshaler.SentinelNaN, "--", math.NaN(), true, nil},
+		{marshaler.SentinelNaN, "-inf", math.Inf(-1), true, nil},
+		{marshaler.SentinelError, "NaN", 0, false, strconv.ErrSyntax},
+		{marshaler.SentinelError, "+Inf", 0, false, nil},
+	}
+	for _, tt := range tests {
+		nf := marshaler.NumberFormat{SentinelPolicy: tt.policy}
+		got, err := nf.ParseFloat(tt.in, 64)
+		if !tt.ok {
+			if err == nil {
+				t.Errorf("policy %d: ParseFloat(%q) = %v, want error", tt.policy, tt.in, got)
+			} else if tt.err != nil && numErr(err) != tt.err {
+				t.Errorf("policy %d: ParseFloat(%q) error = %v, want %v", tt.policy, tt.in, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || !(got == tt.want || math.IsNaN(got) && math.IsNaN(tt.want)) {
+			t.Errorf("policy %d: ParseFloat(%q) = %v, %v; want %v", tt.policy, tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestNonFiniteStringRoundTrip(t *testing.T) {
+	defer func(nf marshaler.NumberFormat) { marshaler.DefaultNumberFormat = nf }(marshaler.DefaultNumberFormat)
+	for _, policy := range []marshaler.SentinelPolicy{marshaler.SentinelNone, marshaler.SentinelNaN} {
+		marshaler.DefaultNumberFormat = marshaler.NumberFormat{
+			SentinelPolicy: policy,
+			NonFinite:      marshaler.NonFiniteString,
+		}
+		v := []marshaler.RobustFloat64{marshaler.RobustFloat64(math.NaN()), marshaler.RobustFloat64(math.Inf(1)), marshaler.RobustFloat64(math.Inf(-1)), 2.5}
+		b, err := json.Marshal(v)
+		if err != nil || string(b) != `["NaN","+Inf","-Inf",2.5]` {
+			t.Fatalf("policy %d: Marshal = %s, %v", policy, b, err)
+		}
+		var got []marshaler.RobustFloat64
+		if err := json.Unmarshal(b, &got); err != nil {
+			t.Fatalf("policy %d: Unmarshal(%s): %v", policy, b, err)
+		}
+		if !math.IsNaN(float64(got[0])) || !math.IsInf(float64(got[1]), 1) || !math.IsInf(float64(got[2]), -1) || got[3] != 2.5 {
+			t.Errorf("policy %d: Unmarshal(%s) = %v", policy, b, got)
+		}
+	}
+}
+
+func TestNonFiniteMarshalJSON(t *testing.T) {
+	defer func(nf marshaler.NumberFormat) { marshaler.DefaultNumberFormat = nf }(marshaler.DefaultNumberFormat)
+	marshaler.DefaultNumberFormat = marshaler.NumberFormat{}
+	if _, err := json.Marshal(marshaler.RobustFloat64(math.Inf(1))); err == nil {
+		t.Error("Marshal(+Inf) succeeded with NonFiniteError")
+	}
+	marshaler.DefaultNumberFormat.NonFinite = marshaler.NonFiniteNull
+	if b, err := json.Marshal(marshaler.RobustFloat32(math.NaN())); err != nil || string(b) != "null" {
+		t.Errorf("Marshal(NaN) = %s, %v; want null", b, err)
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright 2019 Miles Barr <milesbarr2@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marshaler
+
+import (
+	"strings"
+	"unicode"
+)
+
+// A SuffixMode selects the magnitude suffixes, such as the "M" in "1.2M",
+// that are accepted when parsing numbers. Suffixes are expanded exactly, so
+// "1.2M" parses as the integer 1200000.
+type SuffixMode int
+
+const (
+	// SuffixNone accepts no magnitude suffixes.
+	SuffixNone SuffixMode = iota
+
+	// SuffixShortScale accepts the short-scale suffixes used in finance, in
+	// any case: K and thousand (10^3), M, MM, MN and million (10^6), B, BN
+	// and billion (10^9), and T, TN and trillion (10^12).
+	SuffixShortScale
+
+	// SuffixSI accepts the SI prefixes, which are case sensitive: k or K
+	// (10^3), M (10^6), G (10^9), T (10^12), P (10^15) and E (10^18), and
+	// m (10^-3), u or µ (10^-6), n (10^-9) and p (10^-12).
+	SuffixSI
+)
+
+// shortScaleSuffixes are the suffixes accepted by SuffixShortScale, in lower
+// case and longest first.
+var shortScaleSuffixes = []struct {
+	suffix string
+	exp    int
+}{
+	{"trillion", 12},
+	{"thousand", 3},
+	{"billion", 9},
+	{"million", 6},
+	{"bn", 9},
+	{"mm", 6},
+	{"mn", 6},
+	{"tn", 12},
+	{"k", 3},
+	{"m", 6},
+	{"b", 9},
+	{"t", 12},
+}
+
+// siSuffixes are the suffixes accepted by SuffixSI.
+var siSuffixes = map[string]int{
+	"k": 3,
+	"K": 3,
+	"M": 6,
+	"G": 9,
+	"T": 12,
+	"P": 15,
+	"E": 18,
+	"m": -3,
+	"u": -6,
+	"µ": -6,
+	"μ": -6,
+	"n": -9,
+	"p": -12,
+}
+
+// cut removes a magnitude suffix from s and returns the rest of s, without
+// trailing space, and the power of ten the suffix stands for.
+func (m SuffixMode) cut(s string) (string, int) {
+	switch m {
+	case SuffixShortScale:
+		lower := strings.ToLower(s)
+		for _, ss := range shortScaleSuffixes {
+			if strings.HasSuffix(lower, ss.suffix) {
+				if rest, ok := suffixNumber(s[:len(s)-len(ss.suffix)]); ok {
+					return rest, ss.exp
+				}
+			}
+		}
+	case SuffixSI:
+		r := trailingRune(s)
+		if exp, ok := siSuffixes[r]; ok {
+			if rest, ok := suffixNumber(s[:len(s)-len(r)]); ok {
+				return rest, exp
+			}
+		}
+	}
+	return s, 0
+}
+
+// suffixNumber trims trailing space from s and reports whether what remains
+// can carry a magnitude suffix, which requires it to end in a digit or a
+// decimal separator.
+func suffixNumber(s string) (string, bool) {
+	s = strings.TrimRightFunc(s, unicode.IsSpace)
+	if s == "" {
+		return "", false
+	}
+	c := s[len(s)-1]
+	return s, c >= '0' && c <= '9' || c == '.' || c == ','
+}
+
+// splitDecimal splits the decimal number n into its sign, its significant
+// digits and the position of the decimal point within those digits, which
+// may lie outside them.
+func splitDecimal(n string) (neg bool, digits string, point int, ok bool) {
+	if n != "" && (n[0] == '+' || n[0] == '-') {
+		neg, n = n[0] == '-', n[1:]
+	}
+	exp := 0
+	if i := strings.IndexAny(n, "eE"); i >= 0 {
+		e, ok := smallInt(n[i+1:])
+		if !ok {
+			return false, "", 0, false
+		}
+		n, exp = n[:i], e
+	}
+	intPart, fracPart := n, ""
+	if i := strings.IndexByte(n, '.'); i >= 0 {
+		intPart, fracPart = n[:i], n[i+1:]
+	}
+	digits = intPart + fracPart
+	if digits == "" {
+		return false, "", 0, false
+	}
+	for i := 0; i < len(digits); i++ {
+		if digits[i] < '0' || digits[i] > '9' {
+			return false, "", 0, false
+		}
+	}
+	return neg, digits, len(intPart) + exp, true
+}
+
+// smallInt parses a signed exponent of at most four digits.
+func smallInt(s string) (int, bool) {
+	neg := false
+	if s != "" && (s[0] == '+' || s[0] == '-') {
+		neg, s = s[0] == '-', s[1:]
+	}
+	if s == "" || len(s) > 4 {
+		return 0, false
+	}
+	n := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return 0, false
+		}
+		n = n*10 + int(s[i]-'0')
+	}
+	if neg {
+		n = -n
+	}
+	return n, true
+}
@@ -0,0 +1,203 @@
+// Copyright 2019 Miles Barr <milesbarr2@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marshaler
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// A TimeInterval is an interval of time. It can be unmarshaled from an ISO
+// 8601 interval, such as "2019-01-01T09:00:00Z/2019-01-01T17:00:00Z",
+// "2019-01-01T09:00:00Z/PT8H" or "P1D/2019-01-02", or from a range such as
+// "2019-01-01 09:00..2019-01-01 17:00", in the same forms as a DateRange.
+// Bounds are parsed like a FlexibleTime. In JSON a TimeInterval may also be
+// an object with "start" and "end" fields, whose end is exclusive, unlike
+// that of a DateRange, since a time rather than a day ends the interval. It
+// is marshaled in RFC 3339 format, and the zero TimeInterval as an empty
+// string.
+type TimeInterval struct {
+	// Start is the start of the interval, or the zero FlexibleTime if the
+	// interval has no start.
+	Start FlexibleTime
+
+	// End is the end of the interval, or the zero FlexibleTime if the
+	// interval has no end. End is in the interval only if InclusiveEnd is
+	// set.
+	End          FlexibleTime
+	InclusiveEnd bool
+}
+
+// ParseTimeInterval parses s as a TimeInterval.
+func ParseTimeInterval(s string) (TimeInterval, error) {
+	var ti TimeInterval
+	start, end, inclusive, ok := splitInterval(strings.TrimSpace(s), func(side string) bool {
+		var ft FlexibleTime
+		return ft.Set(side) == nil
+	})
+	if ok {
+		ok = ti.setBounds(start, end, inclusive) == nil
+	}
+	if !ok {
+		return TimeInterval{}, fmt.Errorf("marshaler.ParseTimeInterval: cannot parse \"%s\"", s)
+	}
+	return ti, nil
+}
+
+// SetBounds sets ti from separate start and end strings, either of which may
+// be empty for an open-ended interval or an ISO 8601 period relative to the
+// other, such as "PT1H".
+func (ti *TimeInterval) SetBounds(start, end string, inclusiveEnd bool) error {
+	start, end = strings.TrimSpace(start), strings.TrimSpace(end)
+	if err := ti.setBounds(start, end, inclusiveEnd); err != nil {
+		return fmt.Errorf("marshaler.TimeInterval.SetBounds: cannot parse \"%s\", \"%s\"", start, end)
+	}
+	return nil
+}
+
+func (ti *TimeInterval) setBounds(start, end string, inclusiveEnd bool) error {
+	v := TimeInterval{InclusiveEnd: inclusiveEnd}
+	startPeriod, startIsPeriod := parsePeriod(start)
+	endPeriod, endIsPeriod := parsePeriod(end)
+	if startIsPeriod && endIsPeriod {
+		return errInterval
+	}
+	if !startIsPeriod {
+		if err := v.Start.Set(start); err != nil {
+			return err
+		}
+	}
+	if !endIsPeriod {
+		if err := v.End.Set(end); err != nil {
+			return err
+		}
+	}
+	switch {
+	case startIsPeriod:
+		if time.Time(v.End).IsZero() {
+			return errInterval
+		}
+		v.Start = FlexibleTime(startPeriod.addTo(time.Time(v.End), -1))
+	case endIsPeriod:
+		if time.Time(v.Start).IsZero() {
+			return errInterval
+		}
+		v.End = FlexibleTime(endPeriod.addTo(time.Time(v.Start), 1))
+	}
+	*ti = v
+	return nil
+}
+
+// Contains reports whether t is in ti.
+func (ti TimeInterval) Contains(t time.Time) bool {
+	start, end := time.Time(ti.Start), time.Time(ti.End)
+	if !start.IsZero() && t.Before(start) {
+		return false
+	}
+	return end.IsZero() || t.Before(end) || ti.InclusiveEnd && t.Equal(end)
+}
+
+// Intersect returns the time that is in both ti and u. It reports false if
+// there is none.
+func (ti TimeInterval) Intersect(u TimeInterval) (TimeInterval, bool) {
+	v := ti
+	if s := time.Time(u.Start); !s.IsZero() && (time.Time(v.Start).IsZero() || s.After(time.Time(v.Start))) {
+		v.Start = u.Start
+	}
+	if e := time.Time(u.End); !e.IsZero() {
+		switch end := time.Time(v.End); {
+		case end.IsZero() || e.Before(end):
+			v.End, v.InclusiveEnd = u.End, u.InclusiveEnd
+		case e.Equal(end):
+			v.InclusiveEnd = v.InclusiveEnd && u.InclusiveEnd
+		}
+	}
+	start, end := time.Time(v.Start), time.Time(v.End)
+	if !start.IsZero() && !end.IsZero() && (end.Before(start) || end.Equal(start) && !v.InclusiveEnd) {
+		return TimeInterval{}, false
+	}
+	return v, true
+}
+
+// Overlaps reports whether ti and u have any time in common.
+func (ti TimeInterval) Overlaps(u TimeInterval) bool {
+	_, ok := ti.Intersect(u)
+	return ok
+}
+
+// Duration returns the length of ti, or 0 if ti is open-ended.
+func (ti TimeInterval) Duration() time.Duration {
+	start, end := time.Time(ti.Start), time.Time(ti.End)
+	if start.IsZero() || end.IsZero() {
+		return 0
+	}
+	return end.Sub(start)
+}
+
+// String implements the flag.Value interface.
+func (ti TimeInterval) String() string {
+	var start, end string
+	if t := time.Time(ti.Start); !t.IsZero() {
+		start = t.Format(time.RFC3339Nano)
+	}
+	if t := time.Time(ti.End); !t.IsZero() {
+		end = t.Format(time.RFC3339Nano)
+	}
+	return joinInterval(start, end, ti.InclusiveEnd)
+}
+
+// Set implements the flag.Value interface.
+func (ti *TimeInterval) Set(s string) error {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	v, err := ParseTimeInterval(s)
+	if err != nil {
+		return fmt.Errorf("marshaler.TimeInterval.Set: cannot parse \"%s\"", s)
+	}
+	*ti = v
+	return nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (ti TimeInterval) MarshalText() ([]byte, error) {
+	return []byte(ti.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (ti *TimeInterval) UnmarshalText(text []byte) error {
+	return ti.Set(string(text))
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. The value may be
+// a JSON string or an object with "start" and "end" fields, whose end is
+// exclusive.
+func (ti *TimeInterval) UnmarshalJSON(b []byte) error {
+	var bounds struct{ Start, End string }
+	if err := json.Unmarshal(b, &bounds); err == nil {
+		if bounds.Start == "" && bounds.End == "" {
+			return nil
+		}
+		return ti.SetBounds(bounds.Start, bounds.End, false)
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		b = []byte(s)
+	}
+	return ti.UnmarshalText(b)
+}
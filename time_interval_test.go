@@ -0,0 +1,78 @@
+// Copyright 2019 Miles Barr <milesbarr2@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marshaler_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/jadefox10200/marshaler"
+)
+
+func TestParseTimeInterval(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"2019-01-01T09:00:00Z/2019-01-01T17:00:00Z", "2019-01-01T09:00:00Z/2019-01-01T17:00:00Z"},
+		{"2019-01-01T09:00:00Z/PT8H", "2019-01-01T09:00:00Z/2019-01-01T17:00:00Z"},
+		{"P1D/2019-01-02", "2019-01-01T00:00:00Z/2019-01-02T00:00:00Z"},
+		{"2024-01-31T12:00:00Z/P1M", "2024-01-31T12:00:00Z/2024-02-29T12:00:00Z"},
+		{"2024-01-31T12:00:00Z/P1MT1H", "2024-01-31T12:00:00Z/2024-02-29T13:00:00Z"},
+		{"2019-01-01 09:00..2019-01-01 17:00", "2019-01-01T09:00:00Z..2019-01-01T17:00:00Z"},
+		{"2019-01-01T09:00:00Z..", "2019-01-01T09:00:00Z.."},
+		{"../2019-01-01T17:00:00Z", "../2019-01-01T17:00:00Z"},
+	}
+	for _, tt := range tests {
+		ti, err := marshaler.ParseTimeInterval(tt.in)
+		if err != nil {
+			t.Errorf("ParseTimeInterval(%q): %v", tt.in, err)
+			continue
+		}
+		if got := ti.String(); got != tt.want {
+			t.Errorf("ParseTimeInterval(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTimeIntervalRoundTrip(t *testing.T) {
+	start := marshaler.FlexibleTime(time.Date(2019, 1, 1, 9, 0, 0, 0, time.UTC))
+	end := marshaler.FlexibleTime(time.Date(2019, 1, 1, 17, 0, 0, 500, time.UTC))
+	for _, ti := range []marshaler.TimeInterval{
+		{},
+		{InclusiveEnd: true},
+		{Start: start},
+		{Start: start, InclusiveEnd: true},
+		{End: end},
+		{Start: start, End: end},
+		{Start: start, End: end, InclusiveEnd: true},
+	} {
+		b, err := json.Marshal(ti)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got marshaler.TimeInterval
+		if err := json.Unmarshal(b, &got); err != nil {
+			t.Errorf("json.Unmarshal(%s): %v", b, err)
+			continue
+		}
+		if !time.Time(got.Start).Equal(time.Time(ti.Start)) || !time.Time(got.End).Equal(time.Time(ti.End)) || got.InclusiveEnd != ti.InclusiveEnd {
+			t.Errorf("json.Unmarshal(%s) = %+v, want %+v", b, got, ti)
+		}
+	}
+	if b, _ := json.Marshal(marshaler.TimeInterval{}); string(b) != `""` {
+		t.Errorf("json.Marshal of the zero TimeInterval = %s", b)
+	}
+}
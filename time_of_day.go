@@ -0,0 +1,162 @@
+// Copyright 2019 Miles Barr <milesbarr2@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marshaler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A TimeOfDay is a wall clock time without a date or time zone, such as the
+// opening time of a market. It can be unmarshaled from "09:30", "9:30:15.5",
+// "0930", "3pm", "3:30 p.m.", "noon" or "midnight" and is marshaled in
+// HH:MM:SS format, with a fractional second only if needed.
+type TimeOfDay struct {
+	Hour       int
+	Minute     int
+	Second     int
+	Nanosecond int
+}
+
+// TimeOfDayOf returns the time of day of t in its location.
+func TimeOfDayOf(t time.Time) TimeOfDay {
+	return TimeOfDay{t.Hour(), t.Minute(), t.Second(), t.Nanosecond()}
+}
+
+// ParseTimeOfDay parses s as a TimeOfDay. A bare hour, such as "3", is only
+// accepted with "am" or "pm".
+func ParseTimeOfDay(s string) (TimeOfDay, error) {
+	tod, ok := parseTimeOfDay(strings.ToLower(strings.TrimSpace(s)))
+	if !ok {
+		return TimeOfDay{}, fmt.Errorf("marshaler.ParseTimeOfDay: cannot parse \"%s\"", s)
+	}
+	return tod, nil
+}
+
+func parseTimeOfDay(s string) (TimeOfDay, bool) {
+	switch s {
+	case "noon", "midday":
+		return TimeOfDay{Hour: 12}, true
+	case "midnight":
+		return TimeOfDay{}, true
+	}
+	meridiem := ""
+	for _, m := range []string{"a.m.", "p.m.", "am", "pm", "a", "p"} {
+		if strings.HasSuffix(s, m) {
+			meridiem, s = m[:1], strings.TrimSpace(s[:len(s)-len(m)])
+			break
+		}
+	}
+	fields := strings.Split(s, ":")
+	if len(fields) == 1 {
+		switch {
+		case len(s) == 4:
+			fields = []string{s[:2], s[2:]}
+		case meridiem == "" || len(s) > 2:
+			return TimeOfDay{}, false
+		}
+	}
+	if len(fields) > 3 {
+		return TimeOfDay{}, false
+	}
+	var tod TimeOfDay
+	if len(fields) == 3 {
+		sec := fields[2]
+		if i := strings.IndexAny(sec, ".,"); i >= 0 {
+			frac := sec[i+1:]
+			if frac == "" || len(frac) > 9 || !isDigits(frac) {
+				return TimeOfDay{}, false
+			}
+			tod.Nanosecond, _ = strconv.Atoi(frac + strings.Repeat("0", 9-len(frac)))
+			sec = sec[:i]
+		}
+		fields[2] = sec
+	}
+	for i, p := range []*int{&tod.Hour, &tod.Minute, &tod.Second}[:len(fields)] {
+		f := fields[i]
+		if f == "" || len(f) > 2 || i > 0 && len(f) != 2 || !isDigits(f) {
+			return TimeOfDay{}, false
+		}
+		*p, _ = strconv.Atoi(f)
+	}
+	switch meridiem {
+	case "a", "p":
+		if tod.Hour < 1 || tod.Hour > 12 {
+			return TimeOfDay{}, false
+		}
+		tod.Hour %= 12
+		if meridiem == "p" {
+			tod.Hour += 12
+		}
+	}
+	return tod, tod.Valid()
+}
+
+// isDigits reports whether s consists only of ASCII digits.
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isDigitByte(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Valid reports whether t is a valid time of day.
+func (t TimeOfDay) Valid() bool {
+	return t.Hour >= 0 && t.Hour < 24 && t.Minute >= 0 && t.Minute < 60 &&
+		t.Second >= 0 && t.Second < 60 && t.Nanosecond >= 0 && t.Nanosecond < 1e9
+}
+
+// On returns the instant at time of day t on day d, in the location of d.
+func (t TimeOfDay) On(d Date) time.Time {
+	y, m, day := time.Time(d).Date()
+	return time.Date(y, m, day, t.Hour, t.Minute, t.Second, t.Nanosecond, time.Time(d).Location())
+}
+
+// String implements the flag.Value interface.
+func (t TimeOfDay) String() string {
+	s := fmt.Sprintf("%02d:%02d:%02d", t.Hour, t.Minute, t.Second)
+	if t.Nanosecond != 0 {
+		s += strings.TrimRight(fmt.Sprintf(".%09d", t.Nanosecond), "0")
+	}
+	return s
+}
+
+// Set implements the flag.Value interface.
+func (t *TimeOfDay) Set(s string) error {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	tod, err := ParseTimeOfDay(s)
+	if err != nil {
+		return fmt.Errorf("marshaler.TimeOfDay.Set: cannot parse \"%s\"", s)
+	}
+	*t = tod
+	return nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (t TimeOfDay) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (t *TimeOfDay) UnmarshalText(text []byte) error {
+	return t.Set(string(text))
+}
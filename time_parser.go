@@ -0,0 +1,472 @@
+// Copyright 2019 Miles Barr <milesbarr2@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marshaler
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A TimeParser parses the time types. Strings without an explicit time zone
+// are interpreted in the location of the parser, and strings may end with a
+// UTC offset, such as "+02:00" or "-0400", or a time zone name, such as "UTC"
+// or "America/New_York". A time zone abbreviation in a layout, such as
+// "EST", is resolved in the location of the parser if it is known there, and
+// otherwise as a common US or European abbreviation, such as "PDT" or "CEST",
+// or the name of a time zone that uses it. Ambiguous abbreviations, such as
+// "IST", are rejected. A TimeParser also holds the layouts tried, in
+// order, when parsing a FlexibleTime, the order in which ambiguous numeric
+// dates such as "03/04/2019" are read, and the clock against which relative
+// times are resolved. A TimeParser is safe for concurrent use. The zero value
+// interprets strings in UTC, uses the default FlexibleTime layouts, rejects
+// ambiguous numeric dates and uses time.Now as its clock.
+type TimeParser struct {
+	mu    sync.RWMutex
+	loc   *time.Location
+	order DateOrder
+
+	// epoch reports whether a FlexibleTime may be written as a UNIX
+	// timestamp, and epochMin and epochMax bound the plausible dates used to
+	// infer its unit.
+	epoch              bool
+	epochMin, epochMax time.Time
+
+	// excel reports whether a FlexibleTime may be written as a spreadsheet
+	// serial date in the date system excelEpoch.
+	excel      bool
+	excelEpoch ExcelEpoch
+
+	// relative reports whether a FlexibleTime may be written as a relative
+	// time expression, resolved against clock.
+	relative bool
+	clock    func() time.Time
+
+	// layouts is replaced, never modified, so that it can be read without
+	// holding mu. If nil, flexibleTimeLayouts is used.
+	layouts []string
+}
+
+// NewTimeParser returns a TimeParser that interprets strings without an
+// explicit time zone in loc.
+func NewTimeParser(loc *time.Location) *TimeParser {
+	return &TimeParser{loc: loc}
+}
+
+// DefaultTimeParser is the TimeParser used by the Set, UnmarshalText and
+// UnmarshalJSON methods of the time types.
+var DefaultTimeParser = &TimeParser{}
+
+// SetDefaultLocation sets the location in which DefaultTimeParser interprets
+// strings without an explicit time zone.
+func SetDefaultLocation(loc *time.Location) {
+	DefaultTimeParser.SetLocation(loc)
+}
+
+// Location returns the location in which p interprets strings without an
+// explicit time zone.
+func (p *TimeParser) Location() *time.Location {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.loc == nil {
+		return time.UTC
+	}
+	return p.loc
+}
+
+// SetLocation sets the location in which p interprets strings without an
+// explicit time zone.
+func (p *TimeParser) SetLocation(loc *time.Location) {
+	p.mu.Lock()
+	p.loc = loc
+	p.mu.Unlock()
+}
+
+// Layouts returns the layouts p tries, in order, when parsing a
+// FlexibleTime.
+func (p *TimeParser) Layouts() []string {
+	return append([]string(nil), p.layoutList()...)
+}
+
+// SetLayouts replaces the layouts p tries when parsing a FlexibleTime. With
+// no layouts, the default FlexibleTime layouts are restored.
+func (p *TimeParser) SetLayouts(layouts ...string) {
+	p.mu.Lock()
+	p.layouts = nil
+	if len(layouts) > 0 {
+		p.layouts = append([]string(nil), layouts...)
+	}
+	p.mu.Unlock()
+}
+
+// AddLayout adds layout to the end of the layouts p tries when parsing a
+// FlexibleTime, unless it is already present.
+func (p *TimeParser) AddLayout(layout string) {
+	p.InsertLayout(-1, layout)
+}
+
+// InsertLayout inserts layout at index i of the layouts p tries when parsing
+// a FlexibleTime, moving it if it is already present. A negative or
+// out-of-range index adds layout to the end.
+func (p *TimeParser) InsertLayout(i int, layout string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	layouts := removeLayout(p.currentLayouts(), layout)
+	if i < 0 || i > len(layouts) {
+		i = len(layouts)
+	}
+	p.layouts = append(layouts[:i:i], append([]string{layout}, layouts[i:]...)...)
+}
+
+// RemoveLayout removes layout from the layouts p tries when parsing a
+// FlexibleTime. It reports whether the layout was present.
+func (p *TimeParser) RemoveLayout(layout string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	layouts := p.currentLayouts()
+	rest := removeLayout(layouts, layout)
+	p.layouts = rest
+	return len(rest) < len(layouts)
+}
+
+// layoutList returns the layouts p tries when parsing a FlexibleTime. The
+// result must not be modified.
+func (p *TimeParser) layoutList() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.currentLayouts()
+}
+
+// currentLayouts is like layoutList but requires p.mu to be held.
+func (p *TimeParser) currentLayouts() []string {
+	if p.layouts == nil {
+		return flexibleTimeLayouts[:]
+	}
+	return p.layouts
+}
+
+// removeLayout returns a copy of layouts without layout.
+func removeLayout(layouts []string, layout string) []string {
+	rest := make([]string, 0, len(layouts))
+	for _, l := range layouts {
+		if l != layout {
+			rest = append(rest, l)
+		}
+	}
+	return rest
+}
+
+// ParseDate parses s as a Date.
+func (p *TimeParser) ParseDate(s string) (Date, error) {
+	t, err := parseTime(strings.TrimSpace(s), p.Location(), dateLayout)
+	return Date(t), err
+}
+
+// ParseDateTime parses s as a DateTime.
+func (p *TimeParser) ParseDateTime(s string) (DateTime, error) {
+	t, err := parseTime(strings.TrimSpace(s), p.Location(), dateTimeLayout)
+	return DateTime(t), err
+}
+
+// ParseFlexibleTime parses s as a FlexibleTime.
+func (p *TimeParser) ParseFlexibleTime(s string) (FlexibleTime, error) {
+	t, err := p.parseFlexible(strings.TrimSpace(s), p.Location())
+	return FlexibleTime(t), err
+}
+
+// parseFlexible parses s with the layouts of p, falling back to numeric
+// dates and, if enabled, UNIX timestamps, spreadsheet serial dates and
+// relative time expressions.
+// Strings without an explicit time zone are interpreted in loc.
+func (p *TimeParser) parseFlexible(s string, loc *time.Location) (time.Time, error) {
+	t, err := parseTime(s, loc, p.layoutList()...)
+	if err == nil {
+		return t, nil
+	}
+	if _, _, _, ok := splitNumericDate(s); ok {
+		return parseNumericDate(s, loc, p.DateOrder())
+	}
+	if p.epochNumbers() {
+		if t, err := p.ParseEpoch(s); err == nil {
+			return t.In(loc), nil
+		}
+	}
+	if p.excelSerials() {
+		if t, err := parseExcelSerial(s, p.ExcelEpoch(), loc); err == nil {
+			return t, nil
+		}
+	}
+	if p.relativeTimes() {
+		if t, err := p.ParseRelative(s); err == nil {
+			return t.In(loc), nil
+		}
+	}
+	return time.Time{}, err
+}
+
+// isDigitByte reports whether c is an ASCII digit.
+func isDigitByte(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// parseTime parses s with the first of layouts that matches. Strings without
+// an explicit time zone are interpreted in loc, and s may end with a UTC
+// offset or time zone name that is not part of the layout.
+func parseTime(s string, loc *time.Location, layouts ...string) (time.Time, error) {
+	t, err := parseLayouts(s, loc, layouts...)
+	if err == nil {
+		return t, nil
+	}
+	if rest, zone, ok := cutZone(s); ok {
+		for _, layout := range layouts {
+			if t, err := time.ParseInLocation(layout, rest, zone); err == nil {
+				if t, err = resolveAbbreviation(t, layout, rest, zone); err == nil {
+					return t, nil
+				}
+			}
+		}
+	}
+	return time.Time{}, err
+}
+
+// parseLayouts is like parseTime but only accepts a time zone where the
+// layout has one.
+func parseLayouts(s string, loc *time.Location, layouts ...string) (time.Time, error) {
+	var err error
+	for _, layout := range layouts {
+		var t time.Time
+		if t, err = time.ParseInLocation(layout, s, loc); err == nil {
+			if t, err = resolveAbbreviation(t, layout, s, loc); err == nil {
+				return t, nil
+			}
+		}
+	}
+	return time.Time{}, err
+}
+
+// zoneAbbreviations are the UTC offsets, in seconds, of common US and
+// European time zone abbreviations.
+var zoneAbbreviations = map[string]int{
+	"EST":  -5 * 3600,
+	"EDT":  -4 * 3600,
+	"CST":  -6 * 3600,
+	"CDT":  -5 * 3600,
+	"MST":  -7 * 3600,
+	"MDT":  -6 * 3600,
+	"PST":  -8 * 3600,
+	"PDT":  -7 * 3600,
+	"AKST": -9 * 3600,
+	"AKDT": -8 * 3600,
+	"HST":  -10 * 3600,
+	"HDT":  -9 * 3600,
+	"NST":  -(3*3600 + 1800),
+	"NDT":  -(2*3600 + 1800),
+	"WET":  0,
+	"WEST": 1 * 3600,
+	"BST":  1 * 3600,
+	"CET":  1 * 3600,
+	"CEST": 2 * 3600,
+	"EET":  2 * 3600,
+	"EEST": 3 * 3600,
+	"MSK":  3 * 3600,
+}
+
+// ambiguousAbbreviations are abbreviations in use for several time zones
+// with different offsets, which are only resolved in a location that uses
+// them.
+var ambiguousAbbreviations = map[string]bool{
+	"IST": true, // India, Ireland and Israel
+}
+
+// errZoneAbbreviation is reported for a time zone abbreviation that is
+// unknown in the location of the parser and cannot be resolved otherwise.
+var errZoneAbbreviation = errors.New("unknown time zone abbreviation")
+
+// resolveAbbreviation checks t, which was parsed from s with layout in loc.
+// For a time zone abbreviation that is unknown in loc, such as "EST" outside
+// America/New_York, time.ParseInLocation fabricates a location with a zero
+// offset. Instead the abbreviation is resolved from zoneAbbreviations or as
+// the time zone of the same name, if there is one that uses it, and rejected
+// otherwise.
+func resolveAbbreviation(t time.Time, layout, s string, loc *time.Location) (time.Time, error) {
+	name, off := t.Zone()
+	if off != 0 || name == "" || name == "UTC" || name == "GMT" || t.Location() == loc || t.Location().String() != name {
+		return t, nil
+	}
+	if ambiguousAbbreviations[name] || name == "CST" && !usLocation(loc) {
+		return time.Time{}, errZoneAbbreviation
+	}
+	var zone *time.Location
+	if off, ok := zoneAbbreviations[name]; ok {
+		zone = time.FixedZone(name, off)
+	} else if zone = loadLocation(name); zone == nil {
+		return time.Time{}, errZoneAbbreviation
+	}
+	t, err := time.ParseInLocation(layout, s, zone)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if t.Location() != zone {
+		return time.Time{}, errZoneAbbreviation
+	}
+	return t, nil
+}
+
+// usLocation reports whether CST in loc is read as US Central Standard Time
+// rather than, say, China Standard Time: loc is UTC or a US time zone.
+func usLocation(loc *time.Location) bool {
+	name := loc.String()
+	switch name {
+	case "UTC", "CST6CDT", "EST5EDT", "MST7MDT", "PST8PDT":
+		return true
+	}
+	return strings.HasPrefix(name, "America/") || strings.HasPrefix(name, "US/")
+}
+
+// cutZone removes a trailing UTC offset or time zone name from s and returns
+// the rest of s and the location it denotes.
+func cutZone(s string) (string, *time.Location, bool) {
+	if i := strings.LastIndexByte(s, ' '); i >= 0 {
+		if loc, ok := zoneLocation(s[i+1:]); ok {
+			return strings.TrimSpace(s[:i]), loc, true
+		}
+	}
+	if strings.HasSuffix(s, "Z") {
+		return strings.TrimSpace(s[:len(s)-1]), time.UTC, true
+	}
+	// A numeric offset: +hh, +hhmm or +hh:mm.
+	for _, n := range []int{6, 5, 3} {
+		if len(s) <= n {
+			continue
+		}
+		if off, ok := parseOffset(s[len(s)-n:]); ok {
+			return strings.TrimSpace(s[:len(s)-n]), time.FixedZone("", off), true
+		}
+	}
+	return "", nil, false
+}
+
+// zoneLocation returns the location named by the time zone name or UTC
+// offset name.
+func zoneLocation(name string) (*time.Location, bool) {
+	switch name {
+	case "Z", "UTC", "GMT":
+		return time.UTC, true
+	}
+	if off, ok := parseOffset(name); ok {
+		return time.FixedZone("", off), true
+	}
+	if strings.IndexFunc(name, func(r rune) bool { return r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' }) < 0 {
+		return nil, false
+	}
+	loc := loadLocation(name)
+	return loc, loc != nil
+}
+
+// parseOffset parses a UTC offset of the form +hh, +hhmm or +hh:mm and
+// returns it in seconds east of UTC.
+func parseOffset(s string) (int, bool) {
+	if len(s) < 3 || s[0] != '+' && s[0] != '-' {
+		return 0, false
+	}
+	digits := strings.Replace(s[1:], ":", "", 1)
+	if len(s) == 6 && s[3] != ':' || len(digits) != 2 && len(digits) != 4 {
+		return 0, false
+	}
+	n, err := strconv.Atoi(digits)
+	if err != nil || strings.ContainsAny(digits, "+-") {
+		return 0, false
+	}
+	h, m := n, 0
+	if len(digits) == 4 {
+		h, m = n/100, n%100
+	}
+	if h > 14 || m > 59 {
+		return 0, false
+	}
+	off := h*3600 + m*60
+	if s[0] == '-' {
+		off = -off
+	}
+	return off, true
+}
+
+// locations caches the results of time.LoadLocation, including failures.
+// At most maxLocationFailures failures are cached, so that the cache stays
+// bounded however many names are tried.
+var locations struct {
+	sync.Mutex
+	m        map[string]*time.Location
+	failures int
+}
+
+// maxLocationFailures is the number of failed lookups cached by
+// loadLocation.
+const maxLocationFailures = 1024
+
+// loadLocation is like time.LoadLocation but caches the locations it loads.
+// It returns nil if the location cannot be loaded.
+func loadLocation(name string) *time.Location {
+	if name == "" {
+		return time.UTC
+	}
+	if !validLocationName(name) {
+		return nil
+	}
+	locations.Lock()
+	loc, ok := locations.m[name]
+	locations.Unlock()
+	if ok {
+		return loc
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		loc = nil
+	}
+	locations.Lock()
+	defer locations.Unlock()
+	if loc == nil {
+		if locations.failures >= maxLocationFailures {
+			return nil
+		}
+		locations.failures++
+	}
+	if locations.m == nil {
+		locations.m = make(map[string]*time.Location)
+	}
+	locations.m[name] = loc
+	return loc
+}
+
+// validLocationName reports whether name has the shape of a time zone
+// database name, such as "UTC", "EST5EDT" or "America/Port-au-Prince".
+func validLocationName(name string) bool {
+	if len(name) > 64 {
+		return false
+	}
+	for _, part := range strings.Split(name, "/") {
+		if part == "" || part[0] == '.' {
+			return false
+		}
+		for _, r := range part {
+			if !(r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || strings.ContainsRune("_+-.", r)) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,149 @@
+// Copyright 2019 Miles Barr <milesbarr2@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marshaler_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jadefox10200/marshaler"
+)
+
+func TestTimeParserSetLocation(t *testing.T) {
+	var p marshaler.TimeParser
+	if loc := p.Location(); loc != time.UTC {
+		t.Errorf("zero TimeParser location = %s, want UTC", loc)
+	}
+	tokyo := mustLoadLocation(t, "Asia/Tokyo")
+	p.SetLocation(tokyo)
+	ft, err := p.ParseFlexibleTime("2019-07-17 09:30")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := time.Time(ft).Format(time.RFC3339); got != "2019-07-17T09:30:00+09:00" {
+		t.Errorf("ParseFlexibleTime in Asia/Tokyo = %s", got)
+	}
+	// An explicit offset takes precedence over the location.
+	ft, err = p.ParseFlexibleTime("2019-07-17T09:30:00Z")
+	if err != nil || !time.Time(ft).Equal(time.Date(2019, 7, 17, 9, 30, 0, 0, time.UTC)) {
+		t.Errorf("ParseFlexibleTime with offset = %s, %v", time.Time(ft), err)
+	}
+}
+
+func TestTimeParserLayouts(t *testing.T) {
+	var p marshaler.TimeParser
+	defaults := p.Layouts()
+	if len(defaults) == 0 || defaults[0] != "2006-01-02" {
+		t.Fatalf("default layouts = %q", defaults)
+	}
+	defaults[0] = "changed"
+	if p.Layouts()[0] != "2006-01-02" {
+		t.Error("Layouts returned the internal slice")
+	}
+
+	const custom = "02.01.2006"
+	if _, err := p.ParseFlexibleTime("17.07.2019x"); err == nil {
+		t.Fatal("custom layout parsed before it was added")
+	}
+	p.AddLayout(custom + "x")
+	if layouts := p.Layouts(); layouts[len(layouts)-1] != custom+"x" || len(layouts) != len(defaults)+1 {
+		t.Errorf("AddLayout: layouts end with %q, len %d", layouts[len(layouts)-1], len(layouts))
+	}
+	if _, err := p.ParseFlexibleTime("17.07.2019x"); err != nil {
+		t.Errorf("ParseFlexibleTime with added layout: %v", err)
+	}
+	p.AddLayout(custom + "x")
+	if n := len(p.Layouts()); n != len(defaults)+1 {
+		t.Errorf("AddLayout of a present layout: len %d, want %d", n, len(defaults)+1)
+	}
+
+	p.InsertLayout(0, custom+"x")
+	if layouts := p.Layouts(); layouts[0] != custom+"x" || len(layouts) != len(defaults)+1 {
+		t.Errorf("InsertLayout(0) moved layout to %q, len %d", layouts[0], len(layouts))
+	}
+	p.InsertLayout(1, custom)
+	if layouts := p.Layouts(); layouts[1] != custom || layouts[2] != "2006-01-02" {
+		t.Errorf("InsertLayout(1) = %q", layouts[:3])
+	}
+	p.InsertLayout(1000, "15:04")
+	if layouts := p.Layouts(); layouts[len(layouts)-1] != "15:04" {
+		t.Errorf("InsertLayout out of range: last layout %q", layouts[len(layouts)-1])
+	}
+
+	if !p.RemoveLayout(custom + "x") {
+		t.Error("RemoveLayout of a present layout reported false")
+	}
+	if p.RemoveLayout(custom + "x") {
+		t.Error("RemoveLayout of an absent layout reported true")
+	}
+	if _, err := p.ParseFlexibleTime("17.07.2019x"); err == nil {
+		t.Error("ParseFlexibleTime succeeded with a removed layout")
+	}
+
+	p.SetLayouts()
+	if got := p.Layouts(); len(got) != len(defaults) || got[0] != "2006-01-02" {
+		t.Errorf("SetLayouts() did not restore the defaults: %q", got)
+	}
+	p.SetLayouts(custom)
+	if got := p.Layouts(); len(got) != 1 || got[0] != custom {
+		t.Errorf("SetLayouts(%q) = %q", custom, got)
+	}
+	if _, err := p.ParseFlexibleTime("2019-07-17T09:30:00Z"); err == nil {
+		t.Error("ParseFlexibleTime used a layout that was replaced")
+	}
+}
+
+func TestTimeParserConcurrent(t *testing.T) {
+	p := marshaler.NewTimeParser(time.UTC)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				p.AddLayout("2006.01.02")
+				p.ParseFlexibleTime("2019-07-17 09:30 Europe/Paris")
+				p.ParseFlexibleTime("2019-07-17 09:30 No/Such_Zone")
+				p.RemoveLayout("2006.01.02")
+				p.Layouts()
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestTimeParserZoneName(t *testing.T) {
+	var p marshaler.TimeParser
+	ft, err := p.ParseFlexibleTime("2019-07-17 09:30 Europe/Paris")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := time.Time(ft).Format(time.RFC3339); got != "2019-07-17T09:30:00+02:00" {
+		t.Errorf("ParseFlexibleTime with zone name = %s", got)
+	}
+	for _, in := range []string{
+		"2019-07-17 09:30 No/Such_Zone",
+		"2019-07-17 09:30 ../../etc/passwd",
+		"2019-07-17 09:30 Europe//Paris",
+	} {
+		// Repeat to exercise cached failures.
+		for i := 0; i < 2; i++ {
+			if ft, err := p.ParseFlexibleTime(in); err == nil {
+				t.Errorf("ParseFlexibleTime(%q) = %s, want error", in, time.Time(ft))
+			}
+		}
+	}
+}
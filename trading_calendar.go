@@ -0,0 +1,157 @@
+// Copyright 2019 Miles Barr <milesbarr2@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marshaler
+
+import (
+	"fmt"
+	"time"
+)
+
+// A TradingCalendar describes the trading sessions of an exchange. Trading
+// days are weekdays that are not Holidays. Sessions run from Open to Close
+// in the exchange's time zone, or to EarlyClose on the days in EarlyCloses.
+type TradingCalendar struct {
+	Name string
+
+	// TimeZone is the IANA name of the time zone of the exchange, which is
+	// loaded with time.LoadLocation. Programs that run without a time zone
+	// database can import time/tzdata.
+	TimeZone string
+
+	Open       TimeOfDay
+	Close      TimeOfDay
+	EarlyClose TimeOfDay
+
+	Holidays    *RuleCalendar
+	EarlyCloses *RuleCalendar
+}
+
+// closure returns a HolidayRule for a one-off closure.
+func closure(name string, year int, month time.Month, day int) HolidayRule {
+	r := FixedHoliday(name, month, day, ObserveActual)
+	r.FromYear, r.ToYear = year, year
+	return r
+}
+
+// nyseHolidays are the full-day holidays of the New York Stock Exchange
+// since 1998, and the days it closed for other reasons.
+var nyseHolidays = NewRuleCalendar(
+	FixedHoliday("New Year's Day", time.January, 1, ObserveSundayToMonday),
+	HolidayRule{Name: "Martin Luther King Jr. Day", Month: time.January, N: 3, Weekday: time.Monday, FromYear: 1998},
+	NthWeekdayHoliday("Washington's Birthday", time.February, time.Monday, 3),
+	EasterHoliday("Good Friday", -2),
+	NthWeekdayHoliday("Memorial Day", time.May, time.Monday, -1),
+	HolidayRule{Name: "Juneteenth", Month: time.June, Day: 19, Observance: ObserveNearestWeekday, FromYear: 2022},
+	FixedHoliday("Independence Day", time.July, 4, ObserveNearestWeekday),
+	NthWeekdayHoliday("Labor Day", time.September, time.Monday, 1),
+	NthWeekdayHoliday("Thanksgiving Day", time.November, time.Thursday, 4),
+	FixedHoliday("Christmas Day", time.December, 25, ObserveNearestWeekday),
+	closure("September 11 attacks", 2001, time.September, 11),
+	closure("September 11 attacks", 2001, time.September, 12),
+	closure("September 11 attacks", 2001, time.September, 13),
+	closure("September 11 attacks", 2001, time.September, 14),
+	closure("Funeral of Ronald Reagan", 2004, time.June, 11),
+	closure("Funeral of Gerald Ford", 2007, time.January, 2),
+	closure("Hurricane Sandy", 2012, time.October, 29),
+	closure("Hurricane Sandy", 2012, time.October, 30),
+	closure("Funeral of George H. W. Bush", 2018, time.December, 5),
+	closure("Funeral of Jimmy Carter", 2025, time.January, 9),
+)
+
+// nyseEarlyCloses are the days on which the New York Stock Exchange closes
+// early when they are trading days.
+var nyseEarlyCloses = NewRuleCalendar(
+	FixedHoliday("Independence Day Eve", time.July, 3, ObserveActual),
+	HolidayRule{Name: "Day after Thanksgiving", Month: time.November, N: 4, Weekday: time.Thursday, Offset: 1},
+	FixedHoliday("Christmas Eve", time.December, 24, ObserveActual),
+)
+
+// NYSE is the trading calendar of the New York Stock Exchange.
+var NYSE = &TradingCalendar{
+	Name:        "NYSE",
+	TimeZone:    "America/New_York",
+	Open:        TimeOfDay{Hour: 9, Minute: 30},
+	Close:       TimeOfDay{Hour: 16},
+	EarlyClose:  TimeOfDay{Hour: 13},
+	Holidays:    nyseHolidays,
+	EarlyCloses: nyseEarlyCloses,
+}
+
+// NASDAQ is the trading calendar of the Nasdaq Stock Market, which follows
+// the holidays and early closes of the NYSE.
+var NASDAQ = &TradingCalendar{
+	Name:        "NASDAQ",
+	TimeZone:    "America/New_York",
+	Open:        TimeOfDay{Hour: 9, Minute: 30},
+	Close:       TimeOfDay{Hour: 16},
+	EarlyClose:  TimeOfDay{Hour: 13},
+	Holidays:    nyseHolidays,
+	EarlyCloses: nyseEarlyCloses,
+}
+
+// DefaultTradingCalendar is the TradingCalendar used by the methods of
+// TradingDay. It should be configured before any values are unmarshaled.
+var DefaultTradingCalendar = NYSE
+
+// IsHoliday implements the HolidayCalendar interface, so that business days
+// of a Date can be counted in trading days.
+func (tc *TradingCalendar) IsHoliday(d Date) bool {
+	return tc.Holidays != nil && tc.Holidays.IsHoliday(d)
+}
+
+// IsTradingDay reports whether the exchange is open on d.
+func (tc *TradingCalendar) IsTradingDay(d Date) bool {
+	return d.IsBusinessDay(tc)
+}
+
+// IsEarlyClose reports whether the exchange closes early on d.
+func (tc *TradingCalendar) IsEarlyClose(d Date) bool {
+	return tc.EarlyCloses != nil && tc.IsTradingDay(d) && tc.EarlyCloses.IsHoliday(d)
+}
+
+// NextTradingDay returns the first trading day after d.
+func (tc *TradingCalendar) NextTradingDay(d Date) Date {
+	return d.NextBusinessDay(tc)
+}
+
+// PrevTradingDay returns the last trading day before d.
+func (tc *TradingCalendar) PrevTradingDay(d Date) Date {
+	return d.PrevBusinessDay(tc)
+}
+
+// TradingDaysBetween returns the number of trading days after d up to and
+// including e, which is negative if e is before d.
+func (tc *TradingCalendar) TradingDaysBetween(d, e Date) int {
+	return d.BusinessDaysBetween(e, tc)
+}
+
+// Session returns the open and close instants of the session on d in the
+// time zone of the exchange. It returns an error if d is not a trading day.
+func (tc *TradingCalendar) Session(d Date) (open, close time.Time, err error) {
+	loc := loadLocation(tc.TimeZone)
+	if loc == nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("marshaler: cannot load time zone %q of %s", tc.TimeZone, tc.Name)
+	}
+	if !tc.IsTradingDay(d) {
+		return time.Time{}, time.Time{}, fmt.Errorf("marshaler: %s is not a trading day of %s", d, tc.Name)
+	}
+	y, m, day := time.Time(d).Date()
+	d = Date(time.Date(y, m, day, 0, 0, 0, 0, loc))
+	end := tc.Close
+	if tc.IsEarlyClose(d) {
+		end = tc.EarlyClose
+	}
+	return tc.Open.On(d), end.On(d), nil
+}
@@ -0,0 +1,76 @@
+// Copyright 2019 Miles Barr <milesbarr2@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marshaler
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// A TradingDay is a Date on which the exchange of DefaultTradingCalendar is
+// open. It is marshaled and unmarshaled in YYYY-MM-DD format, and days on
+// which the exchange is closed are rejected.
+type TradingDay Date
+
+// String implements the flag.Value interface.
+func (td TradingDay) String() string {
+	return Date(td).String()
+}
+
+// Set implements the flag.Value interface.
+func (td *TradingDay) Set(s string) error {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	var d Date
+	if err := d.Set(s); err != nil || !DefaultTradingCalendar.IsTradingDay(d) {
+		return fmt.Errorf("marshaler.TradingDay.Set: cannot parse \"%s\"", s)
+	}
+	*td = TradingDay(d)
+	return nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (td TradingDay) MarshalText() ([]byte, error) {
+	return []byte(td.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (td *TradingDay) UnmarshalText(text []byte) error {
+	return td.Set(string(text))
+}
+
+// Format wraps time.Time.Format.
+func (td TradingDay) Format(layout string) string {
+	return time.Time(td).Format(layout)
+}
+
+// Next returns the next trading day of DefaultTradingCalendar.
+func (td TradingDay) Next() TradingDay {
+	return TradingDay(DefaultTradingCalendar.NextTradingDay(Date(td)))
+}
+
+// Prev returns the previous trading day of DefaultTradingCalendar.
+func (td TradingDay) Prev() TradingDay {
+	return TradingDay(DefaultTradingCalendar.PrevTradingDay(Date(td)))
+}
+
+// Session returns the open and close instants of the session of
+// DefaultTradingCalendar on td.
+func (td TradingDay) Session() (open, close time.Time, err error) {
+	return DefaultTradingCalendar.Session(Date(td))
+}
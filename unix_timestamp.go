@@ -16,31 +16,58 @@ package marshaler
 
 import (
 	"encoding/json"
-	"strconv"
+	"fmt"
+	"strings"
 	"time"
 )
 
-// A UnixTimestamp is a time.Time that os marshaled and unmarshaled as a UNIX
-// timestamp.
+// A UnixTimestamp is a time.Time that is marshaled and unmarshaled as a UNIX
+// timestamp. It is marshaled as a number of seconds, with a fractional part
+// only if the time is not a whole second, and may be unmarshaled from a JSON
+// number or string with a fractional part or an exponent.
 type UnixTimestamp time.Time
 
+// String implements the flag.Value interface.
 func (ut UnixTimestamp) String() string {
 	return time.Time(ut).String()
 }
 
+// Set implements the flag.Value interface.
+func (ut *UnixTimestamp) Set(s string) error {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	t, err := parseEpoch(s, 9)
+	if err != nil {
+		return fmt.Errorf("marshaler.UnixTimestamp.Set: cannot parse \"%s\"", s)
+	}
+	*ut = UnixTimestamp(t)
+	return nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (ut UnixTimestamp) MarshalText() ([]byte, error) {
+	return []byte(formatEpoch(time.Time(ut), 9)), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (ut *UnixTimestamp) UnmarshalText(text []byte) error {
+	return ut.Set(string(text))
+}
+
 // MarshalJSON implements the json.Marshaler interface.
 func (ut UnixTimestamp) MarshalJSON() ([]byte, error) {
-	return []byte(strconv.FormatInt(time.Time(ut).Unix(), 10)), nil
+	return ut.MarshalText()
 }
 
 // UnmarshalJSON implements the json.Unmarshaler interface.
 func (ut *UnixTimestamp) UnmarshalJSON(b []byte) error {
-	var i int64
-	if err := json.Unmarshal(b, &i); err != nil {
-		return err
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		b = []byte(s)
 	}
-	*ut = UnixTimestamp(time.Unix(i, 0))
-	return nil
+	return ut.UnmarshalText(b)
 }
 
 // Format wraps time.Time.Format.
@@ -16,31 +16,62 @@ package marshaler
 
 import (
 	"encoding/json"
+	"fmt"
 	"strconv"
+	"strings"
 	"time"
 )
 
 // A UnixTimestampMS is a time.Time that is marshaled and unmarshaled as a UNIX
-// timestamp in milliseconds (ms).
+// timestamp in milliseconds (ms). It is marshaled as an integer, and may be
+// unmarshaled from a JSON number or string with a fractional part or an
+// exponent.
 type UnixTimestampMS time.Time
 
+// String implements the flag.Value interface.
 func (utms UnixTimestampMS) String() string {
 	return time.Time(utms).String()
 }
 
+// Set implements the flag.Value interface.
+func (utms *UnixTimestampMS) Set(s string) error {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	t, err := parseEpoch(s, 6)
+	if err != nil {
+		return fmt.Errorf("marshaler.UnixTimestampMS.Set: cannot parse \"%s\"", s)
+	}
+	*utms = UnixTimestampMS(t)
+	return nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (utms UnixTimestampMS) MarshalText() ([]byte, error) {
+	// Unlike UnixNano, this neither overflows outside the years 1678 to 2262
+	// nor rounds times before 1970 toward zero.
+	t := time.Time(utms)
+	return []byte(strconv.FormatInt(t.Unix()*1000+int64(t.Nanosecond())/1000000, 10)), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (utms *UnixTimestampMS) UnmarshalText(text []byte) error {
+	return utms.Set(string(text))
+}
+
 // MarshalJSON implements the json.Marshaler interface.
 func (utms UnixTimestampMS) MarshalJSON() ([]byte, error) {
-	return []byte(strconv.FormatInt(time.Time(utms).UnixNano()/1000000, 10)), nil
+	return utms.MarshalText()
 }
 
 // UnmarshalJSON implements the json.Unmarshaler interface.
 func (utms *UnixTimestampMS) UnmarshalJSON(b []byte) error {
-	var i int64
-	if err := json.Unmarshal(b, &i); err != nil {
-		return err
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		b = []byte(s)
 	}
-	*utms = UnixTimestampMS(time.Unix(i/1000, i%1000*1000000))
-	return nil
+	return utms.UnmarshalText(b)
 }
 
 // Format wraps time.Time.Format.
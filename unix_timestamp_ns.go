@@ -16,31 +16,81 @@ package marshaler
 
 import (
 	"encoding/json"
+	"fmt"
+	"math"
 	"strconv"
+	"strings"
 	"time"
 )
 
 // A UnixTimestampNS is a time.Time that is marshaled and unmarshaled as a UNIX
-// timestamp in nanoseconds (ns).
+// timestamp in nanoseconds (ns). It is marshaled as an integer, and may be
+// unmarshaled from a JSON number or string with a fractional part or an
+// exponent. Times outside the years 1678 to 2262 cannot be marshaled.
 type UnixTimestampNS time.Time
 
+// String implements the flag.Value interface.
 func (utns UnixTimestampNS) String() string {
 	return time.Time(utns).String()
 }
 
+// Set implements the flag.Value interface.
+func (utns *UnixTimestampNS) Set(s string) error {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	t, err := parseEpoch(s, 0)
+	if err != nil {
+		return fmt.Errorf("marshaler.UnixTimestampNS.Set: cannot parse \"%s\"", s)
+	}
+	*utns = UnixTimestampNS(t)
+	return nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (utns UnixTimestampNS) MarshalText() ([]byte, error) {
+	n, ok := unixNano(time.Time(utns))
+	if !ok {
+		return nil, fmt.Errorf("marshaler.UnixTimestampNS.MarshalText: %s is out of range", utns)
+	}
+	return []byte(strconv.FormatInt(n, 10)), nil
+}
+
+// unixNano is like time.Time.UnixNano but reports whether the result fits
+// in an int64 rather than overflowing.
+func unixNano(t time.Time) (int64, bool) {
+	sec, nsec := t.Unix(), int64(t.Nanosecond())
+	if sec < 0 && nsec > 0 {
+		sec, nsec = sec+1, nsec-1000000000
+	}
+	if sec > math.MaxInt64/1000000000 || sec < math.MinInt64/1000000000 {
+		return 0, false
+	}
+	n := sec * 1000000000
+	if nsec > 0 && n > math.MaxInt64-nsec || nsec < 0 && n < math.MinInt64-nsec {
+		return 0, false
+	}
+	return n + nsec, true
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (utns *UnixTimestampNS) UnmarshalText(text []byte) error {
+	return utns.Set(string(text))
+}
+
 // MarshalJSON implements the json.Marshaler interface.
 func (utns UnixTimestampNS) MarshalJSON() ([]byte, error) {
-	return []byte(strconv.FormatInt(time.Time(utns).UnixNano(), 10)), nil
+	return utns.MarshalText()
 }
 
 // UnmarshalJSON implements the json.Unmarshaler interface.
 func (utns *UnixTimestampNS) UnmarshalJSON(b []byte) error {
-	var i int64
-	if err := json.Unmarshal(b, &i); err != nil {
-		return err
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		b = []byte(s)
 	}
-	*utns = UnixTimestampNS(time.Unix(0, i))
-	return nil
+	return utns.UnmarshalText(b)
 }
 
 // Format wraps time.Time.Format.
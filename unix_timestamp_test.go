@@ -0,0 +1,137 @@
+// Copyright 2019 Miles Barr <milesbarr2@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marshaler_test
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/jadefox10200/marshaler"
+)
+
+// A unixTimestamp is implemented by the UNIX timestamp types.
+type unixTimestamp interface {
+	Set(string) error
+	MarshalText() ([]byte, error)
+	UnmarshalText([]byte) error
+}
+
+func TestUnixTimestamps(t *testing.T) {
+	t0 := time.Unix(1700000000, 500000000)
+	pre := time.Unix(-1, 500000000)
+	tests := []struct {
+		name   string
+		new    func() unixTimestamp
+		time   func(unixTimestamp) time.Time
+		in     string
+		want   time.Time
+		marsh  string
+		preOut string
+	}{
+		{
+			"UnixTimestamp",
+			func() unixTimestamp { return new(marshaler.UnixTimestamp) },
+			func(v unixTimestamp) time.Time { return time.Time(*v.(*marshaler.UnixTimestamp)) },
+			"1.7000000005e9", t0, "1700000000.5", "-0.5",
+		},
+		{
+			"UnixTimestampMS",
+			func() unixTimestamp { return new(marshaler.UnixTimestampMS) },
+			func(v unixTimestamp) time.Time { return time.Time(*v.(*marshaler.UnixTimestampMS)) },
+			"1700000000500.0", t0, "1700000000500", "-500",
+		},
+		{
+			"UnixTimestampUS",
+			func() unixTimestamp { return new(marshaler.UnixTimestampUS) },
+			func(v unixTimestamp) time.Time { return time.Time(*v.(*marshaler.UnixTimestampUS)) },
+			"1700000000500000", t0, "1700000000500000", "-500000",
+		},
+		{
+			"UnixTimestampNS",
+			func() unixTimestamp { return new(marshaler.UnixTimestampNS) },
+			func(v unixTimestamp) time.Time { return time.Time(*v.(*marshaler.UnixTimestampNS)) },
+			"17000000005e8", t0, "1700000000500000000", "-500000000",
+		},
+	}
+	for _, tt := range tests {
+		v := tt.new()
+		if err := v.Set(tt.in); err != nil || !tt.time(v).Equal(tt.want) {
+			t.Errorf("%s.Set(%q) = %s, %v; want %s", tt.name, tt.in, tt.time(v), err, tt.want)
+		}
+		if b, err := v.MarshalText(); err != nil || string(b) != tt.marsh {
+			t.Errorf("%s.MarshalText() = %s, %v; want %s", tt.name, b, err, tt.marsh)
+		}
+		if err := v.Set(""); err != nil || !tt.time(v).Equal(tt.want) {
+			t.Errorf("%s.Set(\"\") changed the value to %s, %v", tt.name, tt.time(v), err)
+		}
+		if err := v.Set("soon"); err == nil {
+			t.Errorf("%s.Set(\"soon\") succeeded", tt.name)
+		}
+
+		v = tt.new()
+		if err := v.UnmarshalText([]byte(tt.preOut)); err != nil || !tt.time(v).Equal(pre) {
+			t.Errorf("%s.UnmarshalText(%s) = %s, %v; want %s", tt.name, tt.preOut, tt.time(v), err, pre)
+		}
+		if b, err := v.MarshalText(); err != nil || string(b) != tt.preOut {
+			t.Errorf("%s.MarshalText() before 1970 = %s, %v; want %s", tt.name, b, err, tt.preOut)
+		}
+
+		// JSON numbers and strings, with exponents and fractional parts.
+		for _, in := range []string{tt.marsh, `"` + tt.marsh + `"`, `" ` + tt.in + ` "`} {
+			v = tt.new()
+			if err := json.Unmarshal([]byte(in), v); err != nil || !tt.time(v).Equal(tt.want) {
+				t.Errorf("json.Unmarshal(%s) into %s = %s, %v; want %s", in, tt.name, tt.time(v), err, tt.want)
+			}
+			if b, err := json.Marshal(v); err != nil || string(b) != tt.marsh {
+				t.Errorf("json.Marshal(%s) = %s, %v; want %s", tt.name, b, err, tt.marsh)
+			}
+		}
+	}
+}
+
+func TestUnixTimestampNSRange(t *testing.T) {
+	tests := []struct {
+		t    time.Time
+		want string
+	}{
+		{time.Unix(0, math.MaxInt64), "9223372036854775807"},
+		{time.Unix(0, math.MinInt64), "-9223372036854775808"},
+		{time.Unix(0, math.MaxInt64).Add(time.Nanosecond), ""},
+		{time.Unix(0, math.MinInt64).Add(-time.Nanosecond), ""},
+		{time.Date(2300, 1, 1, 0, 0, 0, 0, time.UTC), ""},
+		{time.Date(1600, 1, 1, 0, 0, 0, 0, time.UTC), ""},
+	}
+	for _, tt := range tests {
+		b, err := marshaler.UnixTimestampNS(tt.t).MarshalText()
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("MarshalText(%s) = %s, want error", tt.t, b)
+			}
+			continue
+		}
+		if err != nil || string(b) != tt.want {
+			t.Errorf("MarshalText(%s) = %s, %v; want %s", tt.t, b, err, tt.want)
+		}
+	}
+	var ns marshaler.UnixTimestampNS
+	if _, err := json.Marshal(marshaler.UnixTimestampNS(time.Date(2300, 1, 1, 0, 0, 0, 0, time.UTC))); err == nil {
+		t.Error("json.Marshal of a time after 2262 succeeded")
+	}
+	if err := ns.Set("-9223372036854775808"); err != nil || !time.Time(ns).Equal(time.Unix(0, math.MinInt64)) {
+		t.Errorf("Set(MinInt64) = %s, %v", time.Time(ns), err)
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2019 Miles Barr <milesbarr2@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marshaler
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A UnixTimestampUS is a time.Time that is marshaled and unmarshaled as a UNIX
+// timestamp in microseconds (us). It is marshaled as an integer, and may be
+// unmarshaled from a JSON number or string with a fractional part or an
+// exponent.
+type UnixTimestampUS time.Time
+
+// String implements the flag.Value interface.
+func (utus UnixTimestampUS) String() string {
+	return time.Time(utus).String()
+}
+
+// Set implements the flag.Value interface.
+func (utus *UnixTimestampUS) Set(s string) error {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	t, err := parseEpoch(s, 3)
+	if err != nil {
+		return fmt.Errorf("marshaler.UnixTimestampUS.Set: cannot parse \"%s\"", s)
+	}
+	*utus = UnixTimestampUS(t)
+	return nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (utus UnixTimestampUS) MarshalText() ([]byte, error) {
+	// See UnixTimestampMS.MarshalText for why UnixNano is not used.
+	t := time.Time(utus)
+	return []byte(strconv.FormatInt(t.Unix()*1000000+int64(t.Nanosecond())/1000, 10)), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (utus *UnixTimestampUS) UnmarshalText(text []byte) error {
+	return utus.Set(string(text))
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (utus UnixTimestampUS) MarshalJSON() ([]byte, error) {
+	return utus.MarshalText()
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (utus *UnixTimestampUS) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		b = []byte(s)
+	}
+	return utus.UnmarshalText(b)
+}
+
+// Format wraps time.Time.Format.
+func (utus UnixTimestampUS) Format(layout string) string {
+	return time.Time(utus).Format(layout)
+}
@@ -0,0 +1,110 @@
+// Copyright 2019 Miles Barr <milesbarr2@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marshaler
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A Year is a calendar year between 0 and 9999. Its text form always has
+// four digits, such as "2019" or "0824", so that two-digit years are not
+// mistaken for the first century. It is marshaled as a JSON number, or as a
+// JSON string holding the text form if DefaultNumberFormat.JSONString is set,
+// and can be unmarshaled from either.
+type Year int
+
+// YearOf returns the year that contains d.
+func YearOf(d Date) Year {
+	return Year(time.Time(d).Year())
+}
+
+// ParseYear parses s, which must have four digits, as a Year.
+func ParseYear(s string) (Year, error) {
+	n := strings.TrimSpace(s)
+	if len(n) != 4 || !isDigits(n) {
+		return 0, fmt.Errorf("marshaler.ParseYear: cannot parse \"%s\"", s)
+	}
+	y, _ := strconv.Atoi(n)
+	return Year(y), nil
+}
+
+// FirstDay returns January 1 of y.
+func (y Year) FirstDay() Date {
+	return newDate(int(y), time.January, 1)
+}
+
+// LastDay returns December 31 of y.
+func (y Year) LastDay() Date {
+	return newDate(int(y), time.December, 31)
+}
+
+// String implements the flag.Value interface.
+func (y Year) String() string {
+	return fmt.Sprintf("%04d", int(y))
+}
+
+// Set implements the flag.Value interface.
+func (y *Year) Set(s string) error {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	v, err := ParseYear(s)
+	if err != nil {
+		return fmt.Errorf("marshaler.Year.Set: cannot parse \"%s\"", s)
+	}
+	*y = v
+	return nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (y Year) MarshalText() ([]byte, error) {
+	return []byte(y.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (y *Year) UnmarshalText(text []byte) error {
+	return y.Set(string(text))
+}
+
+// MarshalJSON implements the json.Marshaler interface. The value is written
+// as a JSON number, or as a JSON string if DefaultNumberFormat.JSONString is
+// set.
+func (y Year) MarshalJSON() ([]byte, error) {
+	if DefaultNumberFormat.JSONString {
+		return []byte(strconv.Quote(y.String())), nil
+	}
+	return []byte(strconv.Itoa(int(y))), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. The value may be
+// a JSON string, which is parsed by ParseYear, or a JSON number between 0
+// and 9999.
+func (y *Year) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		return y.UnmarshalText([]byte(s))
+	}
+	n, err := strconv.Atoi(string(b))
+	if err != nil || n < 0 || n > 9999 {
+		return fmt.Errorf("marshaler.Year.UnmarshalJSON: cannot parse \"%s\"", b)
+	}
+	*y = Year(n)
+	return nil
+}
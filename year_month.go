@@ -0,0 +1,104 @@
+// Copyright 2019 Miles Barr <milesbarr2@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marshaler
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// A YearMonth is a month of a particular year, such as a billing month. It
+// can be unmarshaled from "2019-07", "2019/07", "07/2019", "Jul 2019" or
+// "July 2019" and is marshaled in YYYY-MM format.
+type YearMonth struct {
+	Year  int
+	Month time.Month
+}
+
+// yearMonthLayouts are the layouts accepted by ParseYearMonth.
+var yearMonthLayouts = []string{
+	"2006-01",
+	"2006/01",
+	"01/2006",
+	"1/2006",
+	"01-2006",
+	"Jan 2006",
+	"January 2006",
+	"Jan-2006",
+	"January-2006",
+	"Jan, 2006",
+	"January, 2006",
+	"2006 Jan",
+	"2006 January",
+	"Jan-06",
+	"Jan 06",
+}
+
+// YearMonthOf returns the month that contains d.
+func YearMonthOf(d Date) YearMonth {
+	y, m, _ := time.Time(d).Date()
+	return YearMonth{y, m}
+}
+
+// ParseYearMonth parses s as a YearMonth.
+func ParseYearMonth(s string) (YearMonth, error) {
+	// parseLayouts rather than parseTime, which would read the day of a full
+	// date such as "2019-07-04" as a UTC offset.
+	t, err := parseLayouts(strings.TrimSpace(s), time.UTC, yearMonthLayouts...)
+	if err != nil {
+		return YearMonth{}, fmt.Errorf("marshaler.ParseYearMonth: cannot parse \"%s\"", s)
+	}
+	return YearMonth{t.Year(), t.Month()}, nil
+}
+
+// FirstDay returns the first day of ym.
+func (ym YearMonth) FirstDay() Date {
+	return newDate(ym.Year, ym.Month, 1)
+}
+
+// LastDay returns the last day of ym.
+func (ym YearMonth) LastDay() Date {
+	return newDate(ym.Year, ym.Month+1, 0)
+}
+
+// String implements the flag.Value interface.
+func (ym YearMonth) String() string {
+	return fmt.Sprintf("%04d-%02d", ym.Year, int(ym.Month))
+}
+
+// Set implements the flag.Value interface.
+func (ym *YearMonth) Set(s string) error {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	v, err := ParseYearMonth(s)
+	if err != nil {
+		return fmt.Errorf("marshaler.YearMonth.Set: cannot parse \"%s\"", s)
+	}
+	*ym = v
+	return nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (ym YearMonth) MarshalText() ([]byte, error) {
+	return []byte(ym.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (ym *YearMonth) UnmarshalText(text []byte) error {
+	return ym.Set(string(text))
+}
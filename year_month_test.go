@@ -0,0 +1,40 @@
+// Copyright 2019 Miles Barr <milesbarr2@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marshaler_test
+
+import (
+	"testing"
+
+	"github.com/jadefox10200/marshaler"
+)
+
+func TestParseYearMonth(t *testing.T) {
+	for _, in := range []string{"2019-07", "2019/07", "07/2019", "7/2019", "Jul 2019", "July 2019", "jul-2019", "2019 July", "Jul-19", " 2019-07 "} {
+		ym, err := marshaler.ParseYearMonth(in)
+		if err != nil {
+			t.Errorf("ParseYearMonth(%q): %v", in, err)
+			continue
+		}
+		if got := ym.String(); got != "2019-07" {
+			t.Errorf("ParseYearMonth(%q) = %s, want 2019-07", in, got)
+		}
+	}
+	// Full dates are rejected whatever the day.
+	for _, in := range []string{"", "2019-07-05", "2019-07-15", "2019-07Z", "2019-07 UTC", "2019-13", "July"} {
+		if ym, err := marshaler.ParseYearMonth(in); err == nil {
+			t.Errorf("ParseYearMonth(%q) = %s, want error", in, ym)
+		}
+	}
+}
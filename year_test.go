@@ -0,0 +1,74 @@
+// Copyright 2019 Miles Barr <milesbarr2@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marshaler_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jadefox10200/marshaler"
+)
+
+func TestParseYear(t *testing.T) {
+	for in, want := range map[string]marshaler.Year{"2019": 2019, " 0824 ": 824, "0000": 0, "9999": 9999} {
+		if y, err := marshaler.ParseYear(in); err != nil || y != want {
+			t.Errorf("ParseYear(%q) = %d, %v; want %d", in, y, err, want)
+		}
+	}
+	for _, in := range []string{"", "24", "824", "20190", "-201", "2019.0", "year"} {
+		if y, err := marshaler.ParseYear(in); err == nil {
+			t.Errorf("ParseYear(%q) = %d, want error", in, y)
+		}
+	}
+}
+
+func TestYearRoundTrip(t *testing.T) {
+	for _, y := range []marshaler.Year{0, 824, 2019} {
+		text, err := y.MarshalText()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got marshaler.Year
+		if err := got.UnmarshalText(text); err != nil || got != y {
+			t.Errorf("UnmarshalText(%s) = %d, %v; want %d", text, got, err, y)
+		}
+		b, err := json.Marshal(y)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = -1
+		if err := json.Unmarshal(b, &got); err != nil || got != y {
+			t.Errorf("json.Unmarshal(%s) = %d, %v; want %d", b, got, err, y)
+		}
+	}
+	if s := marshaler.Year(824).String(); s != "0824" {
+		t.Errorf("String() = %s, want 0824", s)
+	}
+	if b, _ := json.Marshal(marshaler.Year(824)); string(b) != "824" {
+		t.Errorf("json.Marshal(824) = %s", b)
+	}
+
+	defer func(nf marshaler.NumberFormat) { marshaler.DefaultNumberFormat = nf }(marshaler.DefaultNumberFormat)
+	marshaler.DefaultNumberFormat.JSONString = true
+	if b, _ := json.Marshal(marshaler.Year(824)); string(b) != `"0824"` {
+		t.Errorf("json.Marshal(824) with JSONString = %s, want \"0824\"", b)
+	}
+	var y marshaler.Year
+	for _, in := range []string{`"24"`, `10000`, `-1`, `2019.5`} {
+		if err := json.Unmarshal([]byte(in), &y); err == nil {
+			t.Errorf("json.Unmarshal(%s) = %d, want error", in, y)
+		}
+	}
+}